@@ -0,0 +1,15 @@
+// (c) 2025, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// SPDX-License-Identifier: Ecosystem
+
+pragma solidity 0.8.27;
+
+interface IBalanceThresholdVerifier {
+    function verifyProof(
+        uint256[2] memory pointA_,
+        uint256[2][2] memory pointB_,
+        uint256[2] memory pointC_,
+        uint256[9] memory publicSignals_
+    ) external view returns (bool verified_);
+}
@@ -82,6 +82,12 @@ struct WithdrawProof {
     uint256[16] publicSignals;
 }
 
+/// @dev publicSignals are the public key, the balance EGCT, the threshold, the upper bound and the challenge
+struct BalanceThresholdProof {
+    ProofPoints proofPoints;
+    uint256[9] publicSignals;
+}
+
 struct TransferInputs {
     EGCT providedBalance;
     EGCT senderEncryptedAmount;
@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/ava-labs/EncryptedERC/pkg/hardhat"
 	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/server"
 )
 
 /*
@@ -13,31 +21,201 @@ import (
 		privateInputs: [],
 		publicInputs: [],
 	}
+	or, keyed by circuit field path
+	{
+		inputs: { "Sender.PublicKey.P.X": "", "Auditor.PCT.Ciphertext[2]": "", ... },
+	}
+	or for the BUILD_* operations the keys and values the assignment is built from, e.g. BUILD_REGISTER
+	{
+		privateKey: "", address: "0x...", chainId: "",
+	}
+
+	Exit codes
+	0 success, 1 unknown failure, 2 invalid input, 3 missing or corrupt circuit artifacts,
+	4 unsatisfied constraints, 5 I/O failure, 6 proof verified as invalid
 */
 
+// exit codes for each error kind, callers rely on these values so they must not change
+var exitCodes = map[helpers.ErrorKind]int{
+	helpers.KindUnknown:    1,
+	helpers.KindInput:      2,
+	helpers.KindArtifact:   3,
+	helpers.KindConstraint: 4,
+	helpers.KindIO:         5,
+}
+
+const exitInvalidProof = 6
+
+// prints the error either as text or as a JSON object on stderr and exits with the code of its kind
+func fail(err error, asJSON bool) {
+	kind := helpers.KindOf(err)
+	code := exitCodes[kind]
+
+	if asJSON {
+		out, _ := json.Marshal(map[string]interface{}{
+			"error": map[string]interface{}{
+				"kind":      kind.String(),
+				"message":   err.Error(),
+				"exitCode":  code,
+				"retryable": kind == helpers.KindIO,
+			},
+		})
+		fmt.Fprintln(os.Stderr, string(out))
+	} else {
+		fmt.Fprintf(os.Stderr, "Error (%s): %v\n", kind, err)
+	}
+
+	os.Exit(code)
+}
+
 func main() {
-	operation := flag.String("operation", "", "Circuit Name [REGISTER,TRANSFER,MINT,WITHDRAW]")
+	operation := flag.String("operation", "", "Circuit Name [REGISTER,BUILD_REGISTER,TRANSFER,BUILD_TRANSFER,MINT,BUILD_MINT,WITHDRAW,BURN,VERIFY,EXPORT_VK,EXPORT_PROOF,BSGS_TABLE,DERIVE_KEY,KEYGEN,IMPORT,EXPORT_PUBLIC,CHANGE_PASSWORD,AUDIT_DECRYPT,DECODE_EVENTS,AUDITOR_ROTATION,ROTATE_AUDITOR,VERIFY_HANDOVER,BALANCE_THRESHOLD,BUILD_BALANCE_THRESHOLD,EGCT_DISCLOSURE,PCT_DISCLOSURE,DISCLOSE,VERIFY_DISCLOSURE,SERVE,PHASE1_INIT,PHASE1_IMPORT,PHASE1_CONTRIBUTE,PHASE1_BEACON,PHASE1_VERIFY,CEREMONY_INIT,CEREMONY_CONTRIBUTE,CEREMONY_BEACON,CEREMONY_VERIFY,CEREMONY_FINALIZE]")
 	input := flag.String("input", "", "Stringified JSON input")
 	output := flag.String("output", "", "Name of the circuit output file (output.json)")
 	csPath := flag.String("cs", "", "Path to the circuit cs.r1cs")
 	pkPath := flag.String("pk", "", "Path to the circuit pk.pk")
-	isNew := flag.Bool("new", false, "Generate new circuit")
-	shouldExtract := flag.Bool("extract", false, "Extract the circuit")
+	vkPath := flag.String("vk", "", "Path to the serialized verifying key vk.vk")
+	isNew := flag.Bool("new", false, "Generate new circuit with a single-party setup (testing only, use the CEREMONY_* operations for production keys)")
+	shouldExtract := flag.Bool("extract", false, "Extract the circuit, the verifying key is required (generated with -new or read from -vk)")
+	allowMissingManifest := flag.Bool("allow-missing-manifest", false, "Accept artifacts without a manifest, extracted before manifests were introduced")
+	dryRun := flag.Bool("dry-run", false, "Only check the inputs against the circuit and report the failing checks, without proving")
+	snarkjs := flag.Bool("snarkjs", false, "Also write the proof as snarkjs <output>_proof.json and <output>_public.json")
+	addr := flag.String("addr", "127.0.0.1:8590", "SERVE: TCP address to listen on")
+	socket := flag.String("socket", "", "SERVE: Unix socket to listen on instead of TCP")
+	dir := flag.String("dir", ".", "Directory the circuit artifacts are extracted to and read from when -cs and -pk are not given, and of the PHASE1_* and CEREMONY_* ceremonies")
+	circuitNames := flag.String("circuits", "", "SERVE: Comma separated circuits to load (default: all found in -dir)")
+	maxConcurrent := flag.Int("max-concurrent", 1, "SERVE: Maximum number of proofs generated concurrently")
+	circuit := flag.String("circuit", "", "CEREMONY_*: Circuit name [REGISTER,TRANSFER,MINT,WITHDRAW,BURN,AUDITOR_ROTATION,BALANCE_THRESHOLD,EGCT_DISCLOSURE,PCT_DISCLOSURE]")
+	phase1Dir := flag.String("phase1", "", "CEREMONY_INIT: Directory of the phase-1 ceremony, closed by its beacon or imported. CEREMONY_VERIFY: verify the phase-1 ceremony again (optional)")
+	power := flag.Int("power", 0, "PHASE1_INIT: The phase-1 SRS supports circuits of up to 2^n constraints. PHASE1_IMPORT: Truncate the SRS to 2^n (default: every power of the file)")
+	ptau := flag.String("ptau", "", "PHASE1_IMPORT: snarkjs powers of tau file (.ptau) of an external phase-1 ceremony")
+	participant := flag.String("participant", "", "PHASE1_CONTRIBUTE, CEREMONY_CONTRIBUTE: Name of the contributor recorded in the transcript")
+	beacon := flag.String("beacon", "", "PHASE1_BEACON, CEREMONY_BEACON: Hex encoded public random beacon")
+	beaconIterations := flag.Int("beacon-iterations", 10, "PHASE1_BEACON, CEREMONY_BEACON: The beacon is hashed 2^n times")
+	tableSize := flag.Int("table-size", 20, "BSGS_TABLE: The table holds 2^n baby steps, decoding values up to 2^m takes 2^(m-n) giant steps")
+	table := flag.String("table", "", "BUILD_TRANSFER, BUILD_BALANCE_THRESHOLD, DISCLOSE: BSGS table decrypting the balance or amount when it is not given in the input")
+	maxBalance := flag.Uint64("max-balance", hardhat.DefaultMaxBalance, "BUILD_TRANSFER, BUILD_BALANCE_THRESHOLD, DISCLOSE: Largest balance or amount the table decrypts, the search takes max-balance/2^table-size giant steps")
+	keyFile := flag.String("key-file", "", "DERIVE_KEY, KEYGEN: File holding the hexadecimal secp256k1 key signing the registration message")
+	keystore := flag.String("keystore", "", "Keystore holding the babyjub private key, read by the provers instead of the private key input (Sender, Holder or OldAuditor)")
+	passwordFile := flag.String("password-file", "", "File holding the keystore password (default: $EERC_PASSWORD, then stdin)")
+	newPasswordFile := flag.String("new-password-file", "", "CHANGE_PASSWORD: File holding the new password (default: $EERC_NEW_PASSWORD, then stdin)")
+	lightKDF := flag.Bool("light-kdf", false, "KEYGEN, IMPORT, CHANGE_PASSWORD: Use cheaper scrypt parameters, for test keys only")
+	transactions := flag.String("transactions", "", "AUDIT_DECRYPT, ROTATE_AUDITOR, VERIFY_HANDOVER: JSON or CSV export of the transactions carrying auditor PCTs")
+	logs := flag.String("logs", "", "DECODE_EVENTS, AUDIT_DECRYPT, ROTATE_AUDITOR, VERIFY_HANDOVER: eth_getLogs dump of the EncryptedERC and Registrar events")
+	bundle := flag.String("bundle", "", "VERIFY_HANDOVER: Handover bundle written by ROTATE_AUDITOR")
+	jsonErrors := flag.Bool("json-errors", false, "Print failures as a JSON object on stderr")
 
 	flag.Parse()
 
-	pp := helpers.TestingParams{Input: *input, Output: *output, CsPath: *csPath, PkPath: *pkPath, IsNew: *isNew, Extract: *shouldExtract}
+	pp := helpers.TestingParams{Input: *input, Output: *output, CsPath: *csPath, PkPath: *pkPath, VkPath: *vkPath, Dir: *dir, IsNew: *isNew, Extract: *shouldExtract, DryRun: *dryRun, Snarkjs: *snarkjs, Keystore: *keystore, PasswordFile: *passwordFile, AllowMissingManifest: *allowMissingManifest}
+	kp := hardhat.KeystoreParams{Input: *input, Output: *output, Keystore: *keystore, PasswordFile: *passwordFile, NewPasswordFile: *newPasswordFile, KeyFile: *keyFile, LightKDF: *lightKDF}
+	tp := hardhat.TableParams{Path: *table, MaxValue: *maxBalance}
+	cp := hardhat.CeremonyParams{Circuit: *circuit, Dir: *dir, Phase1Dir: *phase1Dir, Ptau: *ptau, Power: *power, Participant: *participant, Beacon: *beacon, BeaconIterations: *beaconIterations}
 
+	var err error
 	switch *operation {
 	case "REGISTER":
-		hardhat.Register(pp)
+		err = hardhat.Register(pp)
+	case "BUILD_REGISTER":
+		err = hardhat.BuildRegister(pp)
 	case "MINT":
-		hardhat.Mint(pp)
+		err = hardhat.Mint(pp)
+	case "BUILD_MINT":
+		err = hardhat.BuildMint(pp)
 	case "WITHDRAW":
-		hardhat.Withdraw(pp)
+		err = hardhat.Withdraw(pp)
 	case "TRANSFER":
-		hardhat.Transfer(pp)
+		err = hardhat.Transfer(pp)
+	case "BUILD_TRANSFER":
+		err = hardhat.BuildTransfer(pp, tp)
+	case "BURN":
+		err = hardhat.Burn(pp)
+	case "VERIFY":
+		var valid bool
+		valid, err = hardhat.Verify(pp)
+		if err == nil && !valid {
+			os.Exit(exitInvalidProof)
+		}
+	case "EXPORT_VK":
+		err = hardhat.ExportVK(pp)
+	case "EXPORT_PROOF":
+		err = hardhat.ExportProof(pp)
+	case "BSGS_TABLE":
+		err = hardhat.BSGSTable(*output, *tableSize)
+	case "DERIVE_KEY":
+		err = hardhat.DeriveKey(*input, *keyFile, *output)
+	case "KEYGEN":
+		err = hardhat.Keygen(kp)
+	case "IMPORT":
+		err = hardhat.Import(kp)
+	case "EXPORT_PUBLIC":
+		err = hardhat.ExportPublic(kp)
+	case "CHANGE_PASSWORD":
+		err = hardhat.ChangePassword(kp)
+	case "AUDIT_DECRYPT":
+		err = hardhat.AuditDecrypt(pp, *transactions, *logs)
+	case "BALANCE_THRESHOLD":
+		err = hardhat.BalanceThreshold(pp)
+	case "BUILD_BALANCE_THRESHOLD":
+		err = hardhat.BuildBalanceThreshold(pp, tp)
+	case "EGCT_DISCLOSURE":
+		err = hardhat.EGCTDisclosure(pp)
+	case "PCT_DISCLOSURE":
+		err = hardhat.PCTDisclosure(pp)
+	case "DISCLOSE":
+		err = hardhat.Disclose(pp, tp)
+	case "VERIFY_DISCLOSURE":
+		var valid bool
+		valid, err = hardhat.VerifyDisclosure(pp)
+		if err == nil && !valid {
+			os.Exit(exitInvalidProof)
+		}
+	case "AUDITOR_ROTATION":
+		err = hardhat.AuditorRotation(pp)
+	case "ROTATE_AUDITOR":
+		err = hardhat.RotateAuditor(pp, *transactions, *logs)
+	case "VERIFY_HANDOVER":
+		var valid bool
+		valid, err = hardhat.VerifyHandover(pp, *bundle, *transactions, *logs)
+		if err == nil && !valid {
+			os.Exit(exitInvalidProof)
+		}
+	case "DECODE_EVENTS":
+		err = hardhat.DecodeEvents(*logs, *output)
+	case "SERVE":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		cfg := server.Config{Addr: *addr, Socket: *socket, Dir: *dir, MaxConcurrent: *maxConcurrent, AllowMissingManifest: *allowMissingManifest}
+		if *circuitNames != "" {
+			cfg.Circuits = strings.Split(*circuitNames, ",")
+		}
+		err = helpers.NewError(helpers.KindIO, "serving", server.ListenAndServe(ctx, cfg))
+	case "PHASE1_INIT":
+		err = hardhat.Phase1Init(cp)
+	case "PHASE1_IMPORT":
+		err = hardhat.Phase1Import(cp)
+	case "PHASE1_CONTRIBUTE":
+		err = hardhat.Phase1Contribute(cp)
+	case "PHASE1_BEACON":
+		err = hardhat.Phase1Beacon(cp)
+	case "PHASE1_VERIFY":
+		err = hardhat.Phase1Verify(cp)
+	case "CEREMONY_INIT":
+		err = hardhat.CeremonyInit(cp)
+	case "CEREMONY_CONTRIBUTE":
+		err = hardhat.CeremonyContribute(cp)
+	case "CEREMONY_BEACON":
+		err = hardhat.CeremonyBeacon(cp)
+	case "CEREMONY_VERIFY":
+		err = hardhat.CeremonyVerify(cp)
+	case "CEREMONY_FINALIZE":
+		err = hardhat.CeremonyFinalize(cp)
 	default:
-		panic("Invalid operation")
+		err = helpers.NewError(helpers.KindInput, "", fmt.Errorf("invalid operation %q", *operation))
+	}
+
+	if err != nil {
+		fail(err, *jsonErrors)
 	}
 }
@@ -0,0 +1,79 @@
+// Package audit decrypts the auditor PCTs the contracts emit with every mint, transfer and withdraw
+package audit
+
+import (
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+)
+
+// Transaction is an operation carrying an auditor PCT, the sender and receiver are the addresses involved
+// (the receiver of a mint, the sender of a withdraw)
+type Transaction struct {
+	TxHash   string
+	Sender   string
+	Receiver string
+	// nil if the PCT of the row could not be parsed, Error tells why
+	PCT   *poseidon.PCT
+	Error string
+}
+
+// Entry is the outcome of decrypting the auditor PCT of a transaction
+type Entry struct {
+	TxHash   string `json:"txHash"`
+	Sender   string `json:"sender"`
+	Receiver string `json:"receiver"`
+	// decimal amount in token units, empty if the PCT failed to decrypt
+	Amount string `json:"amount,omitempty"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report lists the decrypted amount of every transaction, the PCTs that fail to decrypt are flagged
+// and left out of the total
+type Report struct {
+	AuditorPublicKey babyjub.Point `json:"auditorPublicKey"`
+	Transactions     int           `json:"transactions"`
+	Decrypted        int           `json:"decrypted"`
+	Failed           int           `json:"failed"`
+	TotalAmount      string        `json:"totalAmount"`
+	Entries          []Entry       `json:"entries"`
+}
+
+// Decrypt decrypts the auditor PCT of every transaction with the auditor's private key: the ECDH key
+// privateKey·AuthKey decrypts the amount and the last element of the ciphertext authenticates it,
+// so a PCT encrypted for another key or altered is flagged instead of yielding a wrong amount
+func Decrypt(privateKey *big.Int, transactions []Transaction) (*Report, error) {
+	publicKey, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{AuditorPublicKey: publicKey, Transactions: len(transactions), Entries: make([]Entry, 0, len(transactions))}
+	total := new(big.Int)
+
+	for _, tx := range transactions {
+		entry := Entry{TxHash: tx.TxHash, Sender: tx.Sender, Receiver: tx.Receiver, Error: tx.Error}
+		if tx.PCT != nil {
+			amount, err := tx.PCT.Decrypt(privateKey, 1)
+			if err != nil {
+				entry.Error = "not encrypted for the auditor key or altered: " + err.Error()
+			} else {
+				entry.Amount = amount[0].String()
+				entry.OK = true
+				total.Add(total, amount[0])
+			}
+		}
+
+		if entry.OK {
+			report.Decrypted++
+		} else {
+			report.Failed++
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+
+	report.TotalAmount = total.String()
+	return report, nil
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"encoding/csv"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+)
+
+func TestDecrypt(t *testing.T) {
+	altered := func(modify func(p *poseidon.PCT)) *poseidon.PCT {
+		pct := testPCT(t, auditorKey, 300)
+		modify(pct)
+		return pct
+	}
+
+	txs := []Transaction{
+		{TxHash: "0x01", PCT: testPCT(t, auditorKey, 100)},
+		{TxHash: "0x02", PCT: testPCT(t, auditorKey, 200)},
+		{TxHash: "0x03", PCT: testPCT(t, big.NewInt(5678), 1000)},
+		{TxHash: "0x04", PCT: altered(func(p *poseidon.PCT) { p.Ciphertext[0] = new(big.Int).Add(p.Ciphertext[0], big.NewInt(1)) })},
+		{TxHash: "0x05", PCT: altered(func(p *poseidon.PCT) { p.Ciphertext[3] = new(big.Int).Add(p.Ciphertext[3], big.NewInt(1)) })},
+		{TxHash: "0x06", PCT: altered(func(p *poseidon.PCT) { p.Nonce = new(big.Int).Add(p.Nonce, big.NewInt(1)) })},
+		{TxHash: "0x07", PCT: altered(func(p *poseidon.PCT) { p.AuthKey = testPCT(t, auditorKey, 300).AuthKey })},
+		{TxHash: "0x08", Error: "expected 7 PCT elements, got 5"},
+	}
+
+	report, err := Decrypt(auditorKey, txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Transactions != 8 || report.Decrypted != 2 || report.Failed != 6 {
+		t.Fatalf("expected 2 of 8 transactions decrypted, got %d of %d (%d failed)", report.Decrypted, report.Transactions, report.Failed)
+	}
+	// the flagged PCTs are left out of the total
+	if report.TotalAmount != "300" {
+		t.Fatalf("expected total 300, got %s", report.TotalAmount)
+	}
+
+	for i, e := range report.Entries {
+		if e.TxHash != txs[i].TxHash {
+			t.Fatalf("entry %d: expected %s, got %s", i, txs[i].TxHash, e.TxHash)
+		}
+		if ok := i < 2; e.OK != ok || (len(e.Amount) != 0) != ok || (len(e.Error) == 0) != ok {
+			t.Errorf("entry %d: expected ok %v, got %+v", i, ok, e)
+		}
+	}
+	if report.Entries[0].Amount != "100" || report.Entries[1].Amount != "200" {
+		t.Fatalf("unexpected amounts %s, %s", report.Entries[0].Amount, report.Entries[1].Amount)
+	}
+	if report.Entries[7].Error != txs[7].Error {
+		t.Fatalf("expected the parsing error to be kept, got %q", report.Entries[7].Error)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	report := &Report{Entries: []Entry{
+		{TxHash: "0x01", Sender: "0xa", Receiver: "0xb", Amount: "100", OK: true},
+		{TxHash: "=HYPERLINK(\"http://x\")", Sender: "+1+1", Receiver: "-1", Error: "@SUM(A1)"},
+		{TxHash: "\t=1", Sender: "\r=1", Receiver: "0xb=1"},
+	}}
+
+	path := filepath.Join(t.TempDir(), "report.csv")
+	if err := report.WriteCSV(path); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"txHash", "sender", "receiver", "amount", "ok", "error"},
+		{"0x01", "0xa", "0xb", "100", "true", ""},
+		{"'=HYPERLINK(\"http://x\")", "'+1+1", "'-1", "", "false", "'@SUM(A1)"},
+		{"'\t=1", "'\r=1", "0xb=1", "", "false", ""},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(rows))
+	}
+	for i := range rows {
+		if !slices.Equal(rows[i], expected[i]) {
+			t.Errorf("row %d: expected %q, got %q", i, expected[i], rows[i])
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// columns of the CSV export, in any order, auditorPCT4..6 are read instead of authKeyX, authKeyY and nonce
+// when the PCT is exported in the uint256[7] layout of the contracts
+var csvColumns = []string{"txHash", "sender", "receiver", "auditorPCT0", "auditorPCT1", "auditorPCT2", "auditorPCT3", "authKeyX", "authKeyY", "nonce"}
+
+/*
+transaction of the JSON export, the auditor PCT either holds the 4 ciphertext elements with the auth key
+and the nonce given apart, or all 7 elements as emitted by the contracts
+
+	{ "txHash": "0x...", "sender": "0x...", "receiver": "0x...", "auditorPCT": ["...", "...", "...", "..."], "authKey": ["x", "y"], "nonce": "..." }
+	{ "txHash": "0x...", "sender": "0x...", "receiver": "0x...", "auditorPCT": ["...", "...", "...", "...", "x", "y", "nonce"] }
+*/
+type jsonTransaction struct {
+	TxHash     string     `json:"txHash"`
+	Sender     string     `json:"sender"`
+	Receiver   string     `json:"receiver"`
+	AuditorPCT []string   `json:"auditorPCT"`
+	AuthKey    *[2]string `json:"authKey"`
+	Nonce      string     `json:"nonce"`
+	// set instead of the PCT by the exports listing a transaction that failed upstream
+	Error string `json:"error"`
+}
+
+// ReadTransactions reads a JSON (array of transactions) or CSV (with a header row) export, by file extension.
+// A row whose PCT can not be parsed is kept with its error so the report accounts for every transaction.
+func ReadTransactions(path string) ([]Transaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var txs []Transaction
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		txs, err = ParseJSON(f)
+	case ".csv":
+		txs, err = ParseCSV(f)
+	default:
+		return nil, fmt.Errorf("%s: expected a .json or .csv export", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return txs, nil
+}
+
+// ParseJSON reads a JSON array of transactions, or an object listing them as "transactions" like a handover bundle
+func ParseJSON(r io.Reader) ([]Transaction, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []jsonTransaction
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		var wrapper struct {
+			Transactions []jsonTransaction `json:"transactions"`
+		}
+		if err := json.Unmarshal(raw, &wrapper); err != nil {
+			return nil, err
+		}
+		rows = wrapper.Transactions
+	} else if err := json.Unmarshal(raw, &rows); err != nil {
+		return nil, err
+	}
+
+	txs := make([]Transaction, len(rows))
+	for i, row := range rows {
+		if len(row.AuditorPCT) == 0 && len(row.Error) != 0 {
+			txs[i] = newTransaction(row.TxHash, row.Sender, row.Receiver, nil, errors.New(row.Error))
+			continue
+		}
+		values := row.AuditorPCT
+		if len(values) == 4 {
+			if row.AuthKey == nil || len(row.Nonce) == 0 {
+				txs[i] = newTransaction(row.TxHash, row.Sender, row.Receiver, nil, errors.New("authKey and nonce are required with 4 PCT elements"))
+				continue
+			}
+			values = append(values[:4:4], row.AuthKey[0], row.AuthKey[1], row.Nonce)
+		} else if row.AuthKey != nil || len(row.Nonce) != 0 {
+			txs[i] = newTransaction(row.TxHash, row.Sender, row.Receiver, nil, errors.New("authKey and nonce are part of a 7 element PCT"))
+			continue
+		}
+		pct, err := parsePCT(values)
+		txs[i] = newTransaction(row.TxHash, row.Sender, row.Receiver, pct, err)
+	}
+	return txs, nil
+}
+
+// ParseCSV reads a CSV export with a header row naming the columns
+func ParseCSV(r io.Reader) ([]Transaction, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	// the 7 element layout names the last three columns auditorPCT4..6
+	columns := csvColumns
+	if _, ok := index["authkeyx"]; !ok {
+		columns = append(csvColumns[:7:7], "auditorPCT4", "auditorPCT5", "auditorPCT6")
+	}
+	for _, column := range columns {
+		if _, ok := index[strings.ToLower(column)]; !ok {
+			return nil, fmt.Errorf("missing column %s", column)
+		}
+	}
+
+	var txs []Transaction
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return txs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(column string) string {
+			return strings.TrimSpace(record[index[strings.ToLower(column)]])
+		}
+		values := make([]string, 7)
+		for i, column := range columns[3:] {
+			values[i] = field(column)
+		}
+		pct, err := parsePCT(values)
+		txs = append(txs, newTransaction(field("txHash"), field("sender"), field("receiver"), pct, err))
+	}
+}
+
+func newTransaction(txHash, sender, receiver string, pct *poseidon.PCT, err error) Transaction {
+	tx := Transaction{TxHash: txHash, Sender: sender, Receiver: receiver, PCT: pct}
+	if err != nil {
+		tx.Error = err.Error()
+	}
+	return tx
+}
+
+// parses the uint256[7] layout ciphertext || authKey || nonce
+func parsePCT(values []string) (*poseidon.PCT, error) {
+	if len(values) != 7 {
+		return nil, fmt.Errorf("expected 7 PCT elements, got %d", len(values))
+	}
+
+	elements := make([]*big.Int, len(values))
+	for i, s := range values {
+		v, ok := new(big.Int), false
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+			v, ok = v.SetString(s[2:], 16)
+		} else {
+			v, ok = v.SetString(s, 10)
+		}
+		if !ok || v.Sign() < 0 || v.Cmp(fr.Modulus()) >= 0 {
+			return nil, fmt.Errorf("PCT element %d %q is not a field element", i, s)
+		}
+		elements[i] = v
+	}
+	return poseidon.ParsePCT(elements)
+}
@@ -0,0 +1,209 @@
+package audit
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+)
+
+var auditorKey = big.NewInt(1234)
+
+// PCT of the amount encrypted for the private key
+func testPCT(t *testing.T, privateKey *big.Int, amount int64) *poseidon.PCT {
+	t.Helper()
+	pk, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pct, _, err := poseidon.NewPCT(&pk, []*big.Int{big.NewInt(amount)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pct
+}
+
+// the uint256[7] elements of the PCT, decimal or 0x-prefixed hexadecimal
+func pctStrings(pct *poseidon.PCT, hex bool) []string {
+	values := pct.Values()
+	out := make([]string, len(values))
+	for i, v := range values {
+		if hex {
+			out[i] = fmt.Sprintf("0x%x", v)
+		} else {
+			out[i] = v.String()
+		}
+	}
+	return out
+}
+
+func quoted(values []string) string {
+	return `"` + strings.Join(values, `", "`) + `"`
+}
+
+func assertPCT(t *testing.T, name string, tx Transaction, expected *poseidon.PCT) {
+	t.Helper()
+	if tx.PCT == nil {
+		t.Fatalf("%s: expected a PCT, got error %q", name, tx.Error)
+	}
+	if fmt.Sprint(tx.PCT.Values()) != fmt.Sprint(expected.Values()) {
+		t.Fatalf("%s: expected PCT %v, got %v", name, expected.Values(), tx.PCT.Values())
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	pct := testPCT(t, auditorKey, 500)
+	for _, hex := range []bool{false, true} {
+		values := pctStrings(pct, hex)
+		input := fmt.Sprintf(`[
+			{"txHash": "0x01", "sender": "0xa", "receiver": "0xb", "auditorPCT": [%s]},
+			{"txHash": "0x02", "sender": "0xa", "receiver": "0xb", "auditorPCT": [%s], "authKey": [%s], "nonce": "%s"}
+		]`, quoted(values), quoted(values[:4]), quoted(values[4:6]), values[6])
+
+		txs, err := ParseJSON(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(txs) != 2 {
+			t.Fatalf("expected 2 transactions, got %d", len(txs))
+		}
+		assertPCT(t, "7 elements", txs[0], pct)
+		assertPCT(t, "4 elements with authKey and nonce", txs[1], pct)
+		if txs[0].TxHash != "0x01" || txs[0].Sender != "0xa" || txs[0].Receiver != "0xb" {
+			t.Fatalf("unexpected transaction %+v", txs[0])
+		}
+	}
+
+	// the bundle layout lists the transactions under "transactions"
+	txs, err := ParseJSON(strings.NewReader(fmt.Sprintf(`{"transactions": [{"txHash": "0x01", "auditorPCT": [%s]}]}`, quoted(pctStrings(pct, false)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("expected 1 transaction, got %d", len(txs))
+	}
+	assertPCT(t, "bundle", txs[0], pct)
+}
+
+func TestParseJSONRejects(t *testing.T) {
+	values := pctStrings(testPCT(t, auditorKey, 500), false)
+	tests := []struct {
+		name, row string
+	}{
+		{"4 elements without authKey", fmt.Sprintf(`{"auditorPCT": [%s], "nonce": "%s"}`, quoted(values[:4]), values[6])},
+		{"4 elements without nonce", fmt.Sprintf(`{"auditorPCT": [%s], "authKey": [%s]}`, quoted(values[:4]), quoted(values[4:6]))},
+		{"7 elements with authKey", fmt.Sprintf(`{"auditorPCT": [%s], "authKey": [%s]}`, quoted(values), quoted(values[4:6]))},
+		{"7 elements with nonce", fmt.Sprintf(`{"auditorPCT": [%s], "nonce": "%s"}`, quoted(values), values[6])},
+		{"5 elements", fmt.Sprintf(`{"auditorPCT": [%s]}`, quoted(values[:5]))},
+		{"not a number", fmt.Sprintf(`{"auditorPCT": [%s, "abc"]}`, quoted(values[:6]))},
+		{"not a field element", fmt.Sprintf(`{"auditorPCT": [%s, "%s"]}`, quoted(values[:6]), new(big.Int).Lsh(babyjub.BasePointOrder, 8))},
+		{"auth key not on the curve", fmt.Sprintf(`{"auditorPCT": [%s, "1", "1", "%s"]}`, quoted(values[:4]), values[6])},
+		{"upstream error", `{"txHash": "0x01", "error": "reverted"}`},
+	}
+
+	for _, tt := range tests {
+		// the row is kept with its error so the report accounts for it
+		txs, err := ParseJSON(strings.NewReader("[" + tt.row + "]"))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(txs) != 1 || txs[0].PCT != nil || len(txs[0].Error) == 0 {
+			t.Errorf("%s: expected the row to be kept with an error, got %+v", tt.name, txs)
+		}
+	}
+
+	if _, err := ParseJSON(strings.NewReader(`{"transactions": 1}`)); err == nil {
+		t.Error("expected an error for malformed JSON")
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	pct := testPCT(t, auditorKey, 500)
+	for _, hex := range []bool{false, true} {
+		values := pctStrings(pct, hex)
+
+		// the columns are matched by name in any order and case
+		separated := "nonce,txHash,Sender,receiver,auditorPCT0,auditorPCT1,auditorPCT2,auditorPCT3,authKeyX,authKeyY\n" +
+			values[6] + ",0x01,0xa,0xb," + strings.Join(values[:6], ",") + "\n"
+		full := "txHash,sender,receiver,auditorPCT0,auditorPCT1,auditorPCT2,auditorPCT3,auditorPCT4,auditorPCT5,auditorPCT6\n" +
+			"0x01, 0xa, 0xb, " + strings.Join(values, ", ") + "\n"
+
+		for name, input := range map[string]string{"authKey and nonce columns": separated, "7 PCT columns": full} {
+			txs, err := ParseCSV(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if len(txs) != 1 {
+				t.Fatalf("%s: expected 1 transaction, got %d", name, len(txs))
+			}
+			assertPCT(t, name, txs[0], pct)
+			if txs[0].TxHash != "0x01" || txs[0].Sender != "0xa" || txs[0].Receiver != "0xb" {
+				t.Fatalf("%s: unexpected transaction %+v", name, txs[0])
+			}
+		}
+	}
+}
+
+func TestParseCSVRejects(t *testing.T) {
+	values := pctStrings(testPCT(t, auditorKey, 500), false)
+	row := "0x01,0xa,0xb," + strings.Join(values, ",") + "\n"
+
+	missing := []string{
+		"sender,receiver,auditorPCT0,auditorPCT1,auditorPCT2,auditorPCT3,auditorPCT4,auditorPCT5,auditorPCT6,extra\n",
+		"txHash,sender,receiver,auditorPCT0,auditorPCT1,auditorPCT2,auditorPCT3,auditorPCT4,auditorPCT5,extra\n",
+		"txHash,sender,receiver,auditorPCT0,auditorPCT1,auditorPCT2,auditorPCT3,authKeyX,authKeyY,auditorPCT6\n",
+	}
+	for _, header := range missing {
+		if _, err := ParseCSV(strings.NewReader(header + row)); err == nil || !strings.Contains(err.Error(), "missing column") {
+			t.Errorf("expected a missing column error for %q, got %v", header, err)
+		}
+	}
+
+	if _, err := ParseCSV(strings.NewReader("")); err == nil {
+		t.Error("expected an error without a header")
+	}
+
+	// a malformed row is kept with its error
+	header := "txHash,sender,receiver,auditorPCT0,auditorPCT1,auditorPCT2,auditorPCT3,auditorPCT4,auditorPCT5,auditorPCT6\n"
+	txs, err := ParseCSV(strings.NewReader(header + "0x01,0xa,0xb," + strings.Join(values[:6], ",") + ",abc\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 || txs[0].PCT != nil || len(txs[0].Error) == 0 {
+		t.Fatalf("expected the row to be kept with an error, got %+v", txs)
+	}
+}
+
+func TestReadTransactions(t *testing.T) {
+	dir := t.TempDir()
+	values := pctStrings(testPCT(t, auditorKey, 500), false)
+
+	files := map[string]string{
+		"export.json": fmt.Sprintf(`[{"txHash": "0x01", "auditorPCT": [%s]}]`, quoted(values)),
+		"export.CSV":  "txHash,sender,receiver,auditorPCT0,auditorPCT1,auditorPCT2,auditorPCT3,auditorPCT4,auditorPCT5,auditorPCT6\n0x01,0xa,0xb," + strings.Join(values, ",") + "\n",
+		"export.txt":  "",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"export.json", "export.CSV"} {
+		txs, err := ReadTransactions(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(txs) != 1 || txs[0].PCT == nil {
+			t.Fatalf("%s: expected 1 parsed transaction, got %+v", name, txs)
+		}
+	}
+	if _, err := ReadTransactions(filepath.Join(dir, "export.txt")); err == nil {
+		t.Error("expected an error for an unknown extension")
+	}
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WriteJSON writes the report with its summary
+func (r *Report) WriteJSON(path string) error {
+	out, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// WriteCSV writes one row per transaction, failed PCTs have an empty amount and the reason in the error column.
+// The text columns come from the export, a value a spreadsheet would read as a formula is neutralized.
+func (r *Report) WriteCSV(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"txHash", "sender", "receiver", "amount", "ok", "error"})
+	for _, e := range r.Entries {
+		w.Write([]string{neutralize(e.TxHash), neutralize(e.Sender), neutralize(e.Receiver), e.Amount, strconv.FormatBool(e.OK), neutralize(e.Error)})
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// prefixes a value starting like a formula (=, +, -, @, or a tab or carriage return hiding one) with a quote,
+// so a spreadsheet opening the report shows it as text instead of evaluating it
+func neutralize(s string) string {
+	if len(s) > 0 && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
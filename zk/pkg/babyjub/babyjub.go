@@ -8,6 +8,19 @@ import (
 	"github.com/consensys/gnark/std/algebra/native/twistededwards"
 )
 
+var (
+	// order of the prime subgroup generated by base8
+	BasePointOrder, _ = new(big.Int).SetString("2736030358979909402780800718157159386076813972158567259200215660948447373041", 10)
+
+	// coefficients of the twisted Edwards equation a·x² + y² = 1 + d·x²·y²
+	A = big.NewInt(168700)
+	D = big.NewInt(168696)
+
+	// x and y coordinates of the base point (base8) of the prime subgroup
+	Base8X, _ = new(big.Int).SetString("5299619240641551281634865583518297030282874472190772894086521144482721001553", 10)
+	Base8Y, _ = new(big.Int).SetString("16950150798460657717958625567821834550301663161624707787222815936182638968203", 10)
+)
+
 type BjWrapper struct {
 	Curve          twistededwards.Curve
 	BasePointOrder *big.Int
@@ -23,15 +36,15 @@ func NewBjWrapper(api frontend.API, curveId tedwards.ID) *BjWrapper {
 	}
 
 	// Set the order of the babyjub curve
-	basePointOrder, _ := big.NewInt(0).SetString("2736030358979909402780800718157159386076813972158567259200215660948447373041", 10)
+	basePointOrder := new(big.Int).Set(BasePointOrder)
 
 	// Set the x and y coordinates for the base point (base8) of the babyjub curve
-	baseX, _ := big.NewInt(0).SetString("5299619240641551281634865583518297030282874472190772894086521144482721001553", 10)
-	baseY, _ := big.NewInt(0).SetString("16950150798460657717958625567821834550301663161624707787222815936182638968203", 10)
+	baseX := new(big.Int).Set(Base8X)
+	baseY := new(big.Int).Set(Base8Y)
 
 	// Set the curve parameters for the babyjub curve being used
-	curve.Params().A = big.NewInt(168700)
-	curve.Params().D = big.NewInt(168696)
+	curve.Params().A = new(big.Int).Set(A)
+	curve.Params().D = new(big.Int).Set(D)
 	curve.Params().Base = [2]*big.Int{baseX, baseY}
 
 	return &BjWrapper{
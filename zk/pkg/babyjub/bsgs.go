@@ -0,0 +1,282 @@
+package babyjub
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// ErrOutOfRange is returned when the point is not value·base8 for any value up to the maximum
+var ErrOutOfRange = errors.New("value out of range")
+
+const (
+	tableMagic      = "eERC-BSGS"
+	tableVersion    = 1
+	tableHeaderSize = 32
+	// key (uint64) and baby step index (uint32) of each entry, little endian
+	tableEntrySize = 12
+	// points normalized with a single inversion
+	batchSize = 1024
+)
+
+// Table holds the baby steps j·base8 for j in [0, size) of the baby-step giant-step search,
+// sorted by the low 64 bits of their x coordinate. It is read only and safe for concurrent use.
+type Table struct {
+	size uint64
+	data []byte
+	// releases the memory mapping of a table opened from disk
+	unmap func() error
+	// number of goroutines searching the giant steps
+	Workers int
+}
+
+// NewTable computes the baby steps j·base8 for j in [0, size), decoding a value up to max takes max/size giant steps
+// so a table of √max entries balances memory with search time (2^20 entries, 12MB, for 2^40 token units)
+func NewTable(size uint64) (*Table, error) {
+	if size == 0 || size > 1<<32 {
+		return nil, errors.New("table size must be in [1, 2^32]")
+	}
+
+	type entry struct {
+		key   uint64
+		index uint32
+	}
+	entries := make([]entry, size)
+
+	workers := uint64(runtime.NumCPU())
+	chunk := (size + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := uint64(0); start < size; start += chunk {
+		end := min(start+chunk, size)
+		wg.Add(1)
+		go func(start, end uint64) {
+			defer wg.Done()
+			// baby steps start·base8, (start+1)·base8, ...
+			first := MulWithBasePoint(new(big.Int).SetUint64(start))
+			step := Base8()
+			walk(&first, &step, end-start, func(i uint64, x *fr.Element) bool {
+				entries[start+i] = entry{key: pointKey(x), index: uint32(start + i)}
+				return true
+			})
+		}(start, end)
+	}
+	wg.Wait()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	data := make([]byte, tableHeaderSize+size*tableEntrySize)
+	writeHeader(data, size)
+	for i, e := range entries {
+		off := tableHeaderSize + uint64(i)*tableEntrySize
+		binary.LittleEndian.PutUint64(data[off:], e.key)
+		binary.LittleEndian.PutUint32(data[off+8:], e.index)
+	}
+
+	return &Table{size: size, data: data}, nil
+}
+
+// OpenTable memory maps a table saved with Save, it must be closed to release the mapping
+func OpenTable(path string) (*Table, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := readHeader(data)
+	if err != nil {
+		unmap()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &Table{size: size, data: data, unmap: unmap}, nil
+}
+
+// Save writes the table to the path so it can be opened with OpenTable
+func (t *Table) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if _, err := w.Write(t.data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Close releases the memory mapping of a table opened from disk
+func (t *Table) Close() error {
+	if t.unmap == nil {
+		return nil
+	}
+	unmap := t.unmap
+	t.unmap, t.data = nil, nil
+	return unmap()
+}
+
+// Size returns the number of baby steps in the table
+func (t *Table) Size() uint64 {
+	return t.size
+}
+
+// Decode returns the value with p = value·base8 for value in [0, max], the giant steps are searched in parallel.
+// It returns ErrOutOfRange if no such value exists. max is capped at 2^64-1-size.
+func (t *Table) Decode(p *Point, max uint64) (uint64, error) {
+	if !p.IsOnCurve() {
+		return 0, errors.New("point is not on the babyjub curve")
+	}
+
+	// i·size + j must not overflow, values that large are out of reach of the walk anyway
+	max = min(max, math.MaxUint64-t.size)
+
+	// giant steps i in [0, nbGiant) cover values i·size + j up to max
+	nbGiant := max/t.size + 1
+
+	workers := uint64(t.Workers)
+	if workers == 0 {
+		workers = uint64(runtime.NumCPU())
+	}
+	workers = min(workers, nbGiant)
+	chunk := (nbGiant + workers - 1) / workers
+
+	// -size·base8, each giant step subtracts size·base8
+	var giant Point
+	giant.Neg(&base8)
+	giant.ScalarMul(&giant, new(big.Int).SetUint64(t.size))
+
+	var found atomic.Bool
+	var value uint64
+	var once sync.Once
+	var wg sync.WaitGroup
+
+	for start := uint64(0); start < nbGiant; start += chunk {
+		end := min(start+chunk, nbGiant)
+		wg.Add(1)
+		go func(start, end uint64) {
+			defer wg.Done()
+			// p - start·size·base8
+			var first Point
+			first.ScalarMul(&giant, new(big.Int).SetUint64(start))
+			first.Add(p, &first)
+
+			walk(&first, &giant, end-start, func(i uint64, x *fr.Element) bool {
+				if found.Load() {
+					return false
+				}
+				base := (start + i) * t.size
+				for _, j := range t.lookup(pointKey(x)) {
+					v := base + j
+					if v > max {
+						continue
+					}
+					// keys are truncated so the candidate is checked against the point
+					candidate := MulWithBasePoint(new(big.Int).SetUint64(v))
+					if candidate.Equal(p) {
+						once.Do(func() { value = v })
+						found.Store(true)
+						return false
+					}
+				}
+				return true
+			})
+		}(start, end)
+	}
+	wg.Wait()
+
+	if !found.Load() {
+		return 0, ErrOutOfRange
+	}
+	return value, nil
+}
+
+// DecryptValue decrypts the ciphertext and decodes the value up to max
+func (t *Table) DecryptValue(privateKey *big.Int, ct *Ciphertext, max uint64) (uint64, error) {
+	msg := Decrypt(privateKey, ct)
+	return t.Decode(&msg, max)
+}
+
+// returns the baby step indexes stored under the key
+func (t *Table) lookup(key uint64) []uint64 {
+	entryKey := func(i uint64) uint64 {
+		return binary.LittleEndian.Uint64(t.data[tableHeaderSize+i*tableEntrySize:])
+	}
+
+	i := uint64(sort.Search(int(t.size), func(i int) bool { return entryKey(uint64(i)) >= key }))
+
+	var indexes []uint64
+	for ; i < t.size && entryKey(i) == key; i++ {
+		off := tableHeaderSize + i*tableEntrySize + 8
+		indexes = append(indexes, uint64(binary.LittleEndian.Uint32(t.data[off:])))
+	}
+	return indexes
+}
+
+// calls f with the x coordinate of first + i·step for i in [0, n) until f returns false,
+// the points are walked in projective coordinates and normalized in batches
+func walk(first, step *Point, n uint64, f func(i uint64, x *fr.Element) bool) {
+	var cur, s projective
+	cur.fromAffine(first)
+	s.fromAffine(step)
+
+	xs := make([]fr.Element, batchSize)
+	zs := make([]fr.Element, batchSize)
+
+	for done := uint64(0); done < n; {
+		count := min(uint64(batchSize), n-done)
+		for k := uint64(0); k < count; k++ {
+			xs[k], zs[k] = cur.X, cur.Z
+			cur.add(&cur, &s)
+		}
+
+		zInv := fr.BatchInvert(zs[:count])
+		for k := uint64(0); k < count; k++ {
+			xs[k].Mul(&xs[k], &zInv[k])
+			if !f(done+k, &xs[k]) {
+				return
+			}
+		}
+		done += count
+	}
+}
+
+// low 64 bits of the x coordinate, p and -p have different keys
+func pointKey(x *fr.Element) uint64 {
+	b := x.Bytes()
+	return binary.BigEndian.Uint64(b[len(b)-8:])
+}
+
+func writeHeader(data []byte, size uint64) {
+	copy(data, tableMagic)
+	binary.LittleEndian.PutUint32(data[16:], tableVersion)
+	binary.LittleEndian.PutUint64(data[24:], size)
+}
+
+func readHeader(data []byte) (uint64, error) {
+	if len(data) < tableHeaderSize || string(data[:len(tableMagic)]) != tableMagic {
+		return 0, errors.New("not a baby-step giant-step table")
+	}
+	if version := binary.LittleEndian.Uint32(data[16:]); version != tableVersion {
+		return 0, fmt.Errorf("unsupported table version %d", version)
+	}
+
+	size := binary.LittleEndian.Uint64(data[24:])
+	if size == 0 || size > 1<<32 || uint64(len(data)) != tableHeaderSize+size*tableEntrySize {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return size, nil
+}
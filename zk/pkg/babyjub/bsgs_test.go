@@ -0,0 +1,55 @@
+package babyjub
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestTableDecode(t *testing.T) {
+	for _, size := range []uint64{1, 16, 1 << 10} {
+		table, err := NewTable(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, value := range []uint64{0, 1, size - 1, size, 3*size + 5, 1000} {
+			p := MulWithBasePoint(new(big.Int).SetUint64(value))
+			// the largest bounds must not overflow the giant steps
+			for _, max := range []uint64{value, value + size, math.MaxUint64 - size, math.MaxUint64} {
+				decoded, err := table.Decode(&p, max)
+				if err != nil {
+					t.Fatalf("size %d, max %d: decoding %d: %v", size, max, value, err)
+				}
+				if decoded != value {
+					t.Fatalf("size %d, max %d: decoded %d, expected %d", size, max, decoded, value)
+				}
+			}
+			if value > 0 {
+				if _, err := table.Decode(&p, value-1); !errors.Is(err, ErrOutOfRange) {
+					t.Fatalf("size %d: %d decoded below the maximum %d: %v", size, value, value-1, err)
+				}
+			}
+		}
+	}
+}
+
+func TestTableDecryptValue(t *testing.T) {
+	table, err := NewTable(1 << 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := big.NewInt(987654321)
+	publicKey := MulWithBasePoint(privateKey)
+	ct, err := Encrypt(&publicKey, big.NewInt(123456), big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := table.DecryptValue(privateKey, &ct, 1<<40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 123456 {
+		t.Fatalf("decrypted %d", value)
+	}
+}
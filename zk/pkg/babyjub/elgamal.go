@@ -0,0 +1,134 @@
+package babyjub
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// Ciphertext is the native ElGamal ciphertext (C1, C2) = (r·base8, m + r·publicKey) over the babyjub curve,
+// the EGCT of the circuits and contracts
+type Ciphertext struct {
+	C1 Point `json:"c1"`
+	C2 Point `json:"c2"`
+}
+
+// Encrypt encrypts value·base8 under the public key with the given randomness, like CheckValue re-encrypts it
+func Encrypt(publicKey *Point, value, random *big.Int) (Ciphertext, error) {
+	if value == nil || value.Sign() < 0 || value.Cmp(BasePointOrder) >= 0 {
+		return Ciphertext{}, errors.New("value must be in [0, BasePointOrder)")
+	}
+	msg := MulWithBasePoint(value)
+	return EncryptPoint(publicKey, &msg, random)
+}
+
+// EncryptRandom encrypts value·base8 under the public key with randomness sampled from random (crypto/rand if nil),
+// the randomness is returned as the circuits need it as ValueRandom.R
+func EncryptRandom(publicKey *Point, value *big.Int, random io.Reader) (Ciphertext, *big.Int, error) {
+	r, err := GenerateKey(random)
+	if err != nil {
+		return Ciphertext{}, nil, err
+	}
+	ct, err := Encrypt(publicKey, value, r)
+	if err != nil {
+		return Ciphertext{}, nil, err
+	}
+	return ct, r, nil
+}
+
+// EncryptPoint encrypts the point under the public key with the given randomness,
+// the native counterpart of BjWrapper.ElGamalEncrypt
+func EncryptPoint(publicKey, msg *Point, random *big.Int) (Ciphertext, error) {
+	if random == nil || random.Sign() <= 0 || random.Cmp(BasePointOrder) >= 0 {
+		return Ciphertext{}, errors.New("randomness must be in [1, BasePointOrder)")
+	}
+	if !publicKey.IsOnCurve() || !msg.IsOnCurve() {
+		return Ciphertext{}, errors.New("point is not on the babyjub curve")
+	}
+
+	var ct Ciphertext
+	var shared Point
+	ct.C1 = MulWithBasePoint(random)
+	shared.ScalarMul(publicKey, random)
+	ct.C2.Add(&shared, msg)
+	return ct, nil
+}
+
+// Decrypt returns the encrypted point C2 - privateKey·C1, the native counterpart of BjWrapper.ElGamalDecrypt.
+// Recovering the value from the point needs a discrete log.
+func Decrypt(privateKey *big.Int, ct *Ciphertext) Point {
+	var shared, msg Point
+	shared.ScalarMul(&ct.C1, privateKey)
+	msg.Sub(&ct.C2, &shared)
+	return msg
+}
+
+// IsZero reports an all zero ciphertext, which the contracts use for a balance that was never initialized
+func (c *Ciphertext) IsZero() bool {
+	return c.C1.X.IsZero() && c.C1.Y.IsZero() && c.C2.X.IsZero() && c.C2.Y.IsZero()
+}
+
+// Add sets c = a + b component wise, the sum encrypts the sum of the values, and returns c
+func (c *Ciphertext) Add(a, b *Ciphertext) *Ciphertext {
+	c.C1.Add(&a.C1, &b.C1)
+	c.C2.Add(&a.C2, &b.C2)
+	return c
+}
+
+// Sub sets c = a - b component wise, the difference encrypts the difference of the values, and returns c
+func (c *Ciphertext) Sub(a, b *Ciphertext) *Ciphertext {
+	c.C1.Sub(&a.C1, &b.C1)
+	c.C2.Sub(&a.C2, &b.C2)
+	return c
+}
+
+// AddToBalance returns the balance after EncryptedUserBalances._addToUserBalance adds the amount,
+// an uninitialized (zero) balance is replaced by the amount
+func AddToBalance(balance, amount *Ciphertext) Ciphertext {
+	if balance.C1.X.IsZero() && balance.C1.Y.IsZero() {
+		return *amount
+	}
+	var next Ciphertext
+	next.Add(balance, amount)
+	return next
+}
+
+// SubtractFromBalance returns the balance after EncryptedUserBalances._subtractFromUserBalance subtracts the amount
+func SubtractFromBalance(balance, amount *Ciphertext) Ciphertext {
+	var next Ciphertext
+	next.Sub(balance, amount)
+	return next
+}
+
+// UnmarshalJSON reads {"c1": ["x", "y"], "c2": ["x", "y"]}, the points must be on the curve
+// unless the whole ciphertext is zero as for an uninitialized balance
+func (c *Ciphertext) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		C1 json.RawMessage `json:"c1"`
+		C2 json.RawMessage `json:"c2"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	c1, err := parseCoordinates(raw.C1)
+	if err != nil {
+		return err
+	}
+	c2, err := parseCoordinates(raw.C2)
+	if err != nil {
+		return err
+	}
+
+	if c1[0].Sign() == 0 && c1[1].Sign() == 0 && c2[0].Sign() == 0 && c2[1].Sign() == 0 {
+		*c = Ciphertext{}
+		return nil
+	}
+
+	if c.C1, err = NewPoint(c1[0], c1[1]); err != nil {
+		return err
+	}
+	c.C2, err = NewPoint(c2[0], c2[1])
+	return err
+}
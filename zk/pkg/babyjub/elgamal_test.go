@@ -0,0 +1,209 @@
+package babyjub
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/test"
+	iden3 "github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+// encrypts Value·base8 with ElGamalEncrypt and decrypts it back with ElGamalDecrypt in the circuit
+type elGamalCircuit struct {
+	PrivateKey frontend.Variable
+	PublicKey  [2]frontend.Variable
+	Value      frontend.Variable
+	Random     frontend.Variable
+	C1         [2]frontend.Variable
+	C2         [2]frontend.Variable
+}
+
+func (c *elGamalCircuit) Define(api frontend.API) error {
+	bj := NewBjWrapper(api, tedwards.BN254)
+	msg := bj.MulWithBasePoint(c.Value)
+
+	c1, c2 := bj.ElGamalEncrypt(twistededwards.Point{X: c.PublicKey[0], Y: c.PublicKey[1]}, msg, c.Random)
+	bj.AssertPoint(c1, c.C1[0], c.C1[1])
+	bj.AssertPoint(c2, c.C2[0], c.C2[1])
+
+	decrypted := bj.ElGamalDecrypt(c.C1, c.C2, c.PrivateKey)
+	bj.AssertPoint(decrypted, msg.X, msg.Y)
+	return nil
+}
+
+// decrypts the ciphertext to Value·base8 in the circuit, as CheckBalance does with the balance
+type elGamalDecryptCircuit struct {
+	PrivateKey frontend.Variable
+	Value      frontend.Variable
+	C1         [2]frontend.Variable
+	C2         [2]frontend.Variable
+}
+
+func (c *elGamalDecryptCircuit) Define(api frontend.API) error {
+	bj := NewBjWrapper(api, tedwards.BN254)
+	msg := bj.MulWithBasePoint(c.Value)
+	decrypted := bj.ElGamalDecrypt(c.C1, c.C2, c.PrivateKey)
+	bj.AssertPoint(decrypted, msg.X, msg.Y)
+	return nil
+}
+
+func coordinates(p *Point) [2]frontend.Variable {
+	return [2]frontend.Variable{p.BigX(), p.BigY()}
+}
+
+func iden3Point(p *Point) *iden3.Point {
+	return &iden3.Point{X: p.BigX(), Y: p.BigY()}
+}
+
+// BabyJubJub._add of the contracts, with iden3's addition
+func contractAdd(a, b *Point) *iden3.Point {
+	return iden3.NewPointProjective().Add(iden3Point(a).Projective(), iden3Point(b).Projective()).Affine()
+}
+
+// BabyJubJub._sub of the contracts, the addition of the negated point (-x, y)
+func contractSub(a, b *Point) *iden3.Point {
+	negated := iden3Point(b)
+	negated.X = new(big.Int).Sub(fr.Modulus(), negated.X)
+	return iden3.NewPointProjective().Add(iden3Point(a).Projective(), negated.Projective()).Affine()
+}
+
+func assertPoint(t *testing.T, name string, p *Point, expected *iden3.Point) {
+	t.Helper()
+	if p.BigX().Cmp(expected.X) != 0 || p.BigY().Cmp(expected.Y) != 0 {
+		t.Fatalf("%s %s, expected (%s, %s)", name, p, expected.X, expected.Y)
+	}
+}
+
+func TestElGamalMatchesCircuit(t *testing.T) {
+	random := rand.New(rand.NewSource(3))
+	for _, privateKey := range testKeys(t) {
+		publicKey, err := PublicKey(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value := new(big.Int).Rand(random, big.NewInt(1<<40))
+		ct, r, err := EncryptRandom(&publicKey, value, random)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msg := Decrypt(privateKey, &ct)
+		expected := MulWithBasePoint(value)
+		if !msg.Equal(&expected) {
+			t.Fatalf("key %s: decrypted %s, expected %s", privateKey, msg, expected)
+		}
+
+		assignment := &elGamalCircuit{
+			PrivateKey: privateKey,
+			PublicKey:  coordinates(&publicKey),
+			Value:      value,
+			Random:     r,
+			C1:         coordinates(&ct.C1),
+			C2:         coordinates(&ct.C2),
+		}
+		if err := test.IsSolved(&elGamalCircuit{}, assignment, ecc.BN254.ScalarField()); err != nil {
+			t.Fatalf("key %s: %v", privateKey, err)
+		}
+	}
+
+	// the ciphertext of another value is rejected
+	publicKey := MulWithBasePoint(big.NewInt(5))
+	ct, err := Encrypt(&publicKey, big.NewInt(10), big.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assignment := &elGamalCircuit{PrivateKey: 5, PublicKey: coordinates(&publicKey), Value: 11, Random: 7, C1: coordinates(&ct.C1), C2: coordinates(&ct.C2)}
+	if err := test.IsSolved(&elGamalCircuit{}, assignment, ecc.BN254.ScalarField()); err == nil {
+		t.Fatal("ciphertext of another value accepted")
+	}
+}
+
+// the deposit encryption of the contracts, BabyJubJub.encrypt, uses the randomness 1
+func TestEncryptMatchesContract(t *testing.T) {
+	publicKey := MulWithBasePoint(big.NewInt(1234))
+	ct, err := Encrypt(&publicKey, big.NewInt(1000), big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertPoint(t, "c1", &ct.C1, iden3.B8)
+	msg := MulWithBasePoint(big.NewInt(1000))
+	assertPoint(t, "c2", &ct.C2, contractAdd(&publicKey, &msg))
+}
+
+func TestBalanceUpdateMatchesContract(t *testing.T) {
+	privateKey := big.NewInt(1234)
+	publicKey, err := PublicKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	random := rand.New(rand.NewSource(4))
+
+	deposit, _, err := EncryptRandom(&publicKey, big.NewInt(500), random)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received, _, err := EncryptRandom(&publicKey, big.NewInt(300), random)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spent, _, err := EncryptRandom(&publicKey, big.NewInt(150), random)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// an uninitialized balance takes the first amount as is
+	balance := AddToBalance(&Ciphertext{}, &deposit)
+	if balance != deposit {
+		t.Fatal("uninitialized balance is not replaced by the amount")
+	}
+
+	next := AddToBalance(&balance, &received)
+	assertPoint(t, "added c1", &next.C1, contractAdd(&balance.C1, &received.C1))
+	assertPoint(t, "added c2", &next.C2, contractAdd(&balance.C2, &received.C2))
+	balance = next
+
+	next = SubtractFromBalance(&balance, &spent)
+	assertPoint(t, "subtracted c1", &next.C1, contractSub(&balance.C1, &spent.C1))
+	assertPoint(t, "subtracted c2", &next.C2, contractSub(&balance.C2, &spent.C2))
+	balance = next
+
+	// the updated balance decrypts to 500 + 300 - 150, natively and in the circuit
+	msg := Decrypt(privateKey, &balance)
+	expected := MulWithBasePoint(big.NewInt(650))
+	if !msg.Equal(&expected) {
+		t.Fatalf("balance decrypted to %s, expected %s", msg, expected)
+	}
+	assignment := &elGamalDecryptCircuit{PrivateKey: privateKey, Value: 650, C1: coordinates(&balance.C1), C2: coordinates(&balance.C2)}
+	if err := test.IsSolved(&elGamalDecryptCircuit{}, assignment, ecc.BN254.ScalarField()); err != nil {
+		t.Fatal(err)
+	}
+	assignment.Value = 800
+	if err := test.IsSolved(&elGamalDecryptCircuit{}, assignment, ecc.BN254.ScalarField()); err == nil {
+		t.Fatal("balance decrypted to a stale value")
+	}
+}
+
+func TestEncryptRejectsOutOfRange(t *testing.T) {
+	publicKey := MulWithBasePoint(big.NewInt(5))
+	if _, err := Encrypt(&publicKey, BasePointOrder, big.NewInt(1)); err == nil {
+		t.Error("value BasePointOrder encrypted")
+	}
+	if _, err := Encrypt(&publicKey, big.NewInt(-1), big.NewInt(1)); err == nil {
+		t.Error("negative value encrypted")
+	}
+	if _, err := Encrypt(&publicKey, big.NewInt(1), big.NewInt(0)); err == nil {
+		t.Error("zero randomness accepted")
+	}
+	notOnCurve := Point{}
+	notOnCurve.X.SetOne()
+	if _, err := Encrypt(&notOnCurve, big.NewInt(1), big.NewInt(1)); err == nil {
+		t.Error("public key off the curve accepted")
+	}
+}
@@ -0,0 +1,59 @@
+package babyjub
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// GenerateKey samples a private key uniformly in [1, BasePointOrder), the range CheckPublicKey accepts.
+// The key is rejection sampled instead of reduced so it is not biased towards small values.
+func GenerateKey(random io.Reader) (*big.Int, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+
+	buf := make([]byte, (BasePointOrder.BitLen()+7)/8)
+	excess := uint(len(buf)*8 - BasePointOrder.BitLen())
+
+	for {
+		if _, err := io.ReadFull(random, buf); err != nil {
+			return nil, err
+		}
+		// drop the bits above the order so the rejection probability stays below 1/2
+		buf[0] &= 0xff >> excess
+
+		key := new(big.Int).SetBytes(buf)
+		if key.Sign() > 0 && key.Cmp(BasePointOrder) < 0 {
+			return key, nil
+		}
+	}
+}
+
+// PublicKey derives the public key of the private key as privateKey·base8, like CheckPublicKey
+func PublicKey(privateKey *big.Int) (Point, error) {
+	if err := CheckPrivateKey(privateKey); err != nil {
+		return Point{}, err
+	}
+	return MulWithBasePoint(privateKey), nil
+}
+
+// CheckPrivateKey checks the private key is in [1, BasePointOrder)
+func CheckPrivateKey(privateKey *big.Int) error {
+	if privateKey == nil || privateKey.Sign() <= 0 || privateKey.Cmp(BasePointOrder) >= 0 {
+		return errors.New("private key must be in [1, BasePointOrder)")
+	}
+	return nil
+}
+
+// CheckPublicKey checks the public key is a valid point of the prime subgroup other than the identity
+func CheckPublicKey(publicKey *Point) error {
+	if !publicKey.IsOnCurve() {
+		return errors.New("public key is not on the babyjub curve")
+	}
+	if publicKey.IsIdentity() || !publicKey.IsInSubgroup() {
+		return errors.New("public key is not in the prime subgroup")
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package babyjub
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	iden3 "github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+// proves PublicKey = PrivateKey·base8 with the BjWrapper of the circuits
+type publicKeyCircuit struct {
+	PrivateKey frontend.Variable
+	PublicKey  [2]frontend.Variable
+}
+
+func (c *publicKeyCircuit) Define(api frontend.API) error {
+	bj := NewBjWrapper(api, tedwards.BN254)
+	bj.AssertPoint(bj.MulWithBasePoint(c.PrivateKey), c.PublicKey[0], c.PublicKey[1])
+	return nil
+}
+
+// private keys at the edges of the range CheckPrivateKey accepts and a few generated ones
+func testKeys(t *testing.T) []*big.Int {
+	keys := []*big.Int{
+		big.NewInt(1),
+		big.NewInt(2),
+		new(big.Int).Sub(BasePointOrder, big.NewInt(1)),
+		new(big.Int).Rsh(BasePointOrder, 1),
+	}
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 8; i++ {
+		key, err := GenerateKey(random)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestBaseMatchesIden3(t *testing.T) {
+	if BasePointOrder.Cmp(iden3.SubOrder) != 0 {
+		t.Fatalf("BasePointOrder %s, iden3 %s", BasePointOrder, iden3.SubOrder)
+	}
+	if A.Cmp(iden3.A) != 0 || D.Cmp(iden3.D) != 0 {
+		t.Fatal("curve coefficients differ from iden3")
+	}
+	base := Base8()
+	if base.BigX().Cmp(iden3.B8.X) != 0 || base.BigY().Cmp(iden3.B8.Y) != 0 {
+		t.Fatalf("base8 %s, iden3 (%s, %s)", base, iden3.B8.X, iden3.B8.Y)
+	}
+	if !base.IsInSubgroup() {
+		t.Fatal("base8 is not in the prime subgroup")
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	for i := 0; i < 256; i++ {
+		key, err := GenerateKey(random)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := CheckPrivateKey(key); err != nil {
+			t.Fatalf("key %s: %v", key, err)
+		}
+	}
+
+	// all ones is above the order once the excess bits are dropped, zero is below the range, both are rejected
+	order := BasePointOrder.Bytes()
+	ones := bytes.Repeat([]byte{0xff}, len(order))
+	zero := make([]byte, len(order))
+	key, err := GenerateKey(bytes.NewReader(append(append(ones, zero...), order...)))
+	if err == nil {
+		t.Fatalf("expected the reader to run out, got %s", key)
+	}
+
+	one := make([]byte, len(order))
+	one[len(one)-1] = 1
+	key, err = GenerateKey(bytes.NewReader(append(ones, one...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("expected 1 after rejecting the first sample, got %s", key)
+	}
+}
+
+func TestCheckPrivateKey(t *testing.T) {
+	for _, key := range []*big.Int{nil, big.NewInt(0), big.NewInt(-1), BasePointOrder, new(big.Int).Add(BasePointOrder, big.NewInt(1))} {
+		if err := CheckPrivateKey(key); err == nil {
+			t.Errorf("private key %v accepted", key)
+		}
+		if _, err := PublicKey(key); err == nil {
+			t.Errorf("public key of %v derived", key)
+		}
+	}
+}
+
+func TestPublicKeyMatchesIden3(t *testing.T) {
+	for _, key := range testKeys(t) {
+		publicKey, err := PublicKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := iden3.NewPoint().Mul(key, iden3.B8)
+		if publicKey.BigX().Cmp(expected.X) != 0 || publicKey.BigY().Cmp(expected.Y) != 0 {
+			t.Fatalf("key %s: public key %s, iden3 (%s, %s)", key, publicKey, expected.X, expected.Y)
+		}
+		if err := CheckPublicKey(&publicKey); err != nil {
+			t.Fatalf("key %s: %v", key, err)
+		}
+	}
+}
+
+func TestPublicKeyMatchesCircuit(t *testing.T) {
+	for _, key := range testKeys(t) {
+		publicKey, err := PublicKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assignment := &publicKeyCircuit{PrivateKey: key, PublicKey: [2]frontend.Variable{publicKey.BigX(), publicKey.BigY()}}
+		if err := test.IsSolved(&publicKeyCircuit{}, assignment, ecc.BN254.ScalarField()); err != nil {
+			t.Fatalf("key %s: %v", key, err)
+		}
+	}
+
+	// the circuit rejects the public key of another private key
+	publicKey := MulWithBasePoint(big.NewInt(3))
+	assignment := &publicKeyCircuit{PrivateKey: 2, PublicKey: [2]frontend.Variable{publicKey.BigX(), publicKey.BigY()}}
+	if err := test.IsSolved(&publicKeyCircuit{}, assignment, ecc.BN254.ScalarField()); err == nil {
+		t.Fatal("wrong public key accepted")
+	}
+}
+
+func TestCompressMatchesIden3(t *testing.T) {
+	for _, key := range testKeys(t) {
+		publicKey := MulWithBasePoint(key)
+		// both signs of x
+		for _, p := range []Point{publicKey, *new(Point).Neg(&publicKey)} {
+			compressed := p.Compress()
+			expected := iden3.NewPoint()
+			expected.X, expected.Y = p.BigX(), p.BigY()
+			if compressed != expected.Compress() {
+				t.Fatalf("point %s: compressed %x, iden3 %x", p, compressed, expected.Compress())
+			}
+
+			var decompressed Point
+			if err := decompressed.Decompress(compressed); err != nil {
+				t.Fatal(err)
+			}
+			if !decompressed.Equal(&p) {
+				t.Fatalf("point %s decompressed to %s", p, decompressed)
+			}
+		}
+	}
+}
+
+func TestPointArithmetic(t *testing.T) {
+	a, b := big.NewInt(12345), big.NewInt(67890)
+	pa, pb := MulWithBasePoint(a), MulWithBasePoint(b)
+
+	var sum, diff, identity Point
+	sum.Add(&pa, &pb)
+	if expected := MulWithBasePoint(new(big.Int).Add(a, b)); !sum.Equal(&expected) {
+		t.Fatalf("a·base8 + b·base8 = %s, expected %s", sum, expected)
+	}
+	diff.Sub(&pb, &pa)
+	if expected := MulWithBasePoint(new(big.Int).Sub(b, a)); !diff.Equal(&expected) {
+		t.Fatalf("b·base8 - a·base8 = %s, expected %s", diff, expected)
+	}
+	identity.ScalarMul(&pa, BasePointOrder)
+	if !identity.IsIdentity() {
+		t.Fatalf("order·p = %s", identity)
+	}
+
+	var marshalled Point
+	if err := marshalled.Unmarshal(pa.Marshal()); err != nil || !marshalled.Equal(&pa) {
+		t.Fatalf("unmarshalled %s, %v", marshalled, err)
+	}
+	if _, err := NewPoint(big.NewInt(1), big.NewInt(1)); err == nil {
+		t.Fatal("point off the curve accepted")
+	}
+}
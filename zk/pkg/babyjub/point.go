@@ -0,0 +1,330 @@
+package babyjub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Point is a native (off-circuit) point of the babyjub curve in affine coordinates,
+// the coordinates are elements of the bn254 scalar field like the circuit variables
+type Point struct {
+	X, Y fr.Element
+}
+
+// coordinates of a point with x = X/Z, y = Y/Z
+type projective struct {
+	X, Y, Z fr.Element
+}
+
+var (
+	curveA, curveD fr.Element
+	base8          Point
+)
+
+func init() {
+	curveA.SetBigInt(A)
+	curveD.SetBigInt(D)
+	base8.X.SetBigInt(Base8X)
+	base8.Y.SetBigInt(Base8Y)
+}
+
+// Identity returns the neutral element (0, 1)
+func Identity() Point {
+	var p Point
+	p.Y.SetOne()
+	return p
+}
+
+// Base8 returns the generator of the prime subgroup used by the circuits
+func Base8() Point {
+	return base8
+}
+
+// NewPoint returns the point with the given coordinates, which must be on the curve
+func NewPoint(x, y *big.Int) (Point, error) {
+	var p Point
+	if err := setCoordinate(&p.X, x); err != nil {
+		return p, err
+	}
+	if err := setCoordinate(&p.Y, y); err != nil {
+		return p, err
+	}
+	if !p.IsOnCurve() {
+		return p, errors.New("point is not on the babyjub curve")
+	}
+	return p, nil
+}
+
+func setCoordinate(e *fr.Element, v *big.Int) error {
+	if v.Sign() < 0 || v.Cmp(fr.Modulus()) >= 0 {
+		return fmt.Errorf("coordinate %s is not in the scalar field", v)
+	}
+	e.SetBigInt(v)
+	return nil
+}
+
+// BigX returns the x coordinate as a big integer
+func (p *Point) BigX() *big.Int {
+	return p.X.BigInt(new(big.Int))
+}
+
+// BigY returns the y coordinate as a big integer
+func (p *Point) BigY() *big.Int {
+	return p.Y.BigInt(new(big.Int))
+}
+
+// IsOnCurve checks a·x² + y² = 1 + d·x²·y², the same equation AssertIsOnCurve enforces
+func (p *Point) IsOnCurve() bool {
+	var xx, yy, lhs, rhs fr.Element
+	xx.Square(&p.X)
+	yy.Square(&p.Y)
+
+	lhs.Mul(&curveA, &xx)
+	lhs.Add(&lhs, &yy)
+
+	rhs.Mul(&curveD, &xx)
+	rhs.Mul(&rhs, &yy)
+	rhs.Add(&rhs, new(fr.Element).SetOne())
+
+	return lhs.Equal(&rhs)
+}
+
+// IsInSubgroup checks the point is on the curve and in the prime subgroup generated by base8
+func (p *Point) IsInSubgroup() bool {
+	if !p.IsOnCurve() {
+		return false
+	}
+	var q Point
+	q.ScalarMul(p, BasePointOrder)
+	return q.IsIdentity()
+}
+
+func (p *Point) IsIdentity() bool {
+	return p.X.IsZero() && p.Y.IsOne()
+}
+
+func (p *Point) Equal(q *Point) bool {
+	return p.X.Equal(&q.X) && p.Y.Equal(&q.Y)
+}
+
+// Neg sets p = -a and returns p
+func (p *Point) Neg(a *Point) *Point {
+	p.X.Neg(&a.X)
+	p.Y.Set(&a.Y)
+	return p
+}
+
+// Add sets p = a + b and returns p
+func (p *Point) Add(a, b *Point) *Point {
+	var pa, pb projective
+	pa.fromAffine(a)
+	pb.fromAffine(b)
+	pa.add(&pa, &pb)
+	return pa.toAffine(p)
+}
+
+// Sub sets p = a - b and returns p
+func (p *Point) Sub(a, b *Point) *Point {
+	var nb Point
+	nb.Neg(b)
+	return p.Add(a, &nb)
+}
+
+// ScalarMul sets p = s·a and returns p, s is not reduced like in the circuit ScalarMul
+func (p *Point) ScalarMul(a *Point, s *big.Int) *Point {
+	var acc, base projective
+	acc.X.SetZero()
+	acc.Y.SetOne()
+	acc.Z.SetOne()
+	base.fromAffine(a)
+
+	k := new(big.Int).Abs(s)
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		acc.add(&acc, &acc)
+		if k.Bit(i) == 1 {
+			acc.add(&acc, &base)
+		}
+	}
+
+	acc.toAffine(p)
+	if s.Sign() < 0 {
+		p.Neg(p)
+	}
+	return p
+}
+
+// MulWithBasePoint returns s·base8, the native counterpart of BjWrapper.MulWithBasePoint
+func MulWithBasePoint(s *big.Int) Point {
+	var p Point
+	p.ScalarMul(&base8, s)
+	return p
+}
+
+func (p *projective) fromAffine(a *Point) {
+	p.X.Set(&a.X)
+	p.Y.Set(&a.Y)
+	p.Z.SetOne()
+}
+
+func (p *projective) toAffine(a *Point) *Point {
+	var zInv fr.Element
+	zInv.Inverse(&p.Z)
+	a.X.Mul(&p.X, &zInv)
+	a.Y.Mul(&p.Y, &zInv)
+	return a
+}
+
+// complete addition in projective coordinates (add-2008-bbjlp), also used for doubling
+func (p *projective) add(a, b *projective) *projective {
+	var A, B, C, D, E, F, G, t0, t1 fr.Element
+
+	A.Mul(&a.Z, &b.Z)
+	B.Square(&A)
+	C.Mul(&a.X, &b.X)
+	D.Mul(&a.Y, &b.Y)
+	E.Mul(&curveD, &C)
+	E.Mul(&E, &D)
+	F.Sub(&B, &E)
+	G.Add(&B, &E)
+
+	// X3 = A·F·((X1+Y1)·(X2+Y2) - C - D)
+	t0.Add(&a.X, &a.Y)
+	t1.Add(&b.X, &b.Y)
+	t0.Mul(&t0, &t1)
+	t0.Sub(&t0, &C)
+	t0.Sub(&t0, &D)
+	t0.Mul(&t0, &A)
+	p.X.Mul(&t0, &F)
+
+	// Y3 = A·G·(D - a·C)
+	t1.Mul(&curveA, &C)
+	t1.Sub(&D, &t1)
+	t1.Mul(&t1, &A)
+	p.Y.Mul(&t1, &G)
+
+	// Z3 = F·G
+	p.Z.Mul(&F, &G)
+	return p
+}
+
+// Marshal returns the 64 bytes big endian encoding x || y
+func (p *Point) Marshal() []byte {
+	x, y := p.X.Bytes(), p.Y.Bytes()
+	return append(x[:], y[:]...)
+}
+
+// Unmarshal reads the encoding of Marshal, the point must be on the curve
+func (p *Point) Unmarshal(b []byte) error {
+	if len(b) != 2*fr.Bytes {
+		return fmt.Errorf("expected %d bytes, got %d", 2*fr.Bytes, len(b))
+	}
+	point, err := NewPoint(new(big.Int).SetBytes(b[:fr.Bytes]), new(big.Int).SetBytes(b[fr.Bytes:]))
+	if err != nil {
+		return err
+	}
+	*p = point
+	return nil
+}
+
+// Compress returns the 32 bytes packed encoding of circomlibjs packPoint,
+// y in little endian with the top bit set if x is "negative" (x > (q-1)/2)
+func (p *Point) Compress() [32]byte {
+	y := p.Y.Bytes()
+	var out [32]byte
+	for i := range y {
+		out[i] = y[len(y)-1-i]
+	}
+	if p.X.LexicographicallyLargest() {
+		out[31] |= 0x80
+	}
+	return out
+}
+
+// Decompress reads the encoding of Compress
+func (p *Point) Decompress(b [32]byte) error {
+	negative := b[31]&0x80 != 0
+	b[31] &= 0x7f
+
+	be := make([]byte, 32)
+	for i := range b {
+		be[i] = b[len(b)-1-i]
+	}
+	yBig := new(big.Int).SetBytes(be)
+	var y fr.Element
+	if err := setCoordinate(&y, yBig); err != nil {
+		return err
+	}
+
+	// x² = (1 - y²) / (a - d·y²)
+	var yy, num, den, x fr.Element
+	yy.Square(&y)
+	num.SetOne()
+	num.Sub(&num, &yy)
+	den.Mul(&curveD, &yy)
+	den.Sub(&curveA, &den)
+	if den.IsZero() {
+		return errors.New("point is not on the babyjub curve")
+	}
+	den.Inverse(&den)
+	x.Mul(&num, &den)
+	if x.Sqrt(&x) == nil {
+		return errors.New("point is not on the babyjub curve")
+	}
+	if x.LexicographicallyLargest() != negative {
+		x.Neg(&x)
+	}
+
+	p.X, p.Y = x, y
+	return nil
+}
+
+// MarshalJSON encodes the point as ["x", "y"] decimal strings like the frontend and the circuit inputs
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]string{p.X.String(), p.Y.String()})
+}
+
+// UnmarshalJSON reads ["x", "y"] decimal or 0x prefixed hexadecimal strings, the point must be on the curve
+func (p *Point) UnmarshalJSON(data []byte) error {
+	xy, err := parseCoordinates(data)
+	if err != nil {
+		return err
+	}
+
+	point, err := NewPoint(xy[0], xy[1])
+	if err != nil {
+		return err
+	}
+	*p = point
+	return nil
+}
+
+func parseCoordinates(data []byte) ([2]*big.Int, error) {
+	var coordinates [2]string
+	if err := json.Unmarshal(data, &coordinates); err != nil {
+		return [2]*big.Int{}, err
+	}
+
+	var xy [2]*big.Int
+	for i, c := range coordinates {
+		v, ok := new(big.Int), false
+		if strings.HasPrefix(c, "0x") || strings.HasPrefix(c, "0X") {
+			v, ok = v.SetString(c[2:], 16)
+		} else {
+			v, ok = v.SetString(c, 10)
+		}
+		if !ok {
+			return xy, fmt.Errorf("invalid coordinate %q", c)
+		}
+		xy[i] = v
+	}
+	return xy, nil
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%s, %s)", p.X.String(), p.Y.String())
+}
@@ -0,0 +1,115 @@
+// Package builder assembles the circuit assignments from keys and balances instead of flattened inputs,
+// computing every derived value (public keys, hashes, ciphertexts) the same way the circuit checks it
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+var maxAddress = new(big.Int).Lsh(big.NewInt(1), 160)
+
+func point(p *babyjub.Point) twistededwards.Point {
+	return twistededwards.Point{X: p.BigX(), Y: p.BigY()}
+}
+
+func publicKey(p *babyjub.Point) circuits.PublicKey {
+	return circuits.PublicKey{P: point(p)}
+}
+
+func elGamalCiphertext(ct *babyjub.Ciphertext) circuits.ElGamalCiphertext {
+	return circuits.ElGamalCiphertext{C1: point(&ct.C1), C2: point(&ct.C2)}
+}
+
+func poseidonCiphertext(pct *poseidon.PCT, random *big.Int) circuits.PoseidonCiphertext {
+	var c circuits.PoseidonCiphertext
+	for i := range c.Ciphertext {
+		c.Ciphertext[i] = pct.Ciphertext[i]
+	}
+	c.AuthKey = point(&pct.AuthKey)
+	c.Nonce = pct.Nonce
+	c.Random = random
+	return c
+}
+
+func emittedPCT(pct *poseidon.PCT) circuits.EmittedPCT {
+	var c circuits.EmittedPCT
+	for i := range c.Ciphertext {
+		c.Ciphertext[i] = pct.Ciphertext[i]
+	}
+	c.AuthKey = point(&pct.AuthKey)
+	c.Nonce = pct.Nonce
+	return c
+}
+
+// encrypts the value for the receiver as an EGCT and a PCT with fresh randomness, like CheckValue and CheckPCTReceiver expect
+func newReceiver(pk *babyjub.Point, value *big.Int, random io.Reader) (circuits.Receiver, error) {
+	if err := babyjub.CheckPublicKey(pk); err != nil {
+		return circuits.Receiver{}, fmt.Errorf("receiver: %w", err)
+	}
+
+	egct, r, err := babyjub.EncryptRandom(pk, value, random)
+	if err != nil {
+		return circuits.Receiver{}, err
+	}
+	pct, pctRandom, err := poseidon.NewPCT(pk, []*big.Int{value}, random)
+	if err != nil {
+		return circuits.Receiver{}, err
+	}
+
+	return circuits.Receiver{
+		PublicKey:   publicKey(pk),
+		ValueEGCT:   elGamalCiphertext(&egct),
+		ValueRandom: circuits.Randomness{R: r},
+		PCT:         poseidonCiphertext(pct, pctRandom),
+	}, nil
+}
+
+// encrypts the value for the auditor as a PCT with fresh randomness, like CheckPCTAuditor expects
+func newAuditor(pk *babyjub.Point, value *big.Int, random io.Reader) (circuits.Auditor, *poseidon.PCT, error) {
+	if err := babyjub.CheckPublicKey(pk); err != nil {
+		return circuits.Auditor{}, nil, fmt.Errorf("auditor: %w", err)
+	}
+
+	pct, r, err := poseidon.NewPCT(pk, []*big.Int{value}, random)
+	if err != nil {
+		return circuits.Auditor{}, nil, err
+	}
+	return circuits.Auditor{PublicKey: publicKey(pk), PCT: poseidonCiphertext(pct, r)}, pct, nil
+}
+
+// PublicSignals returns the public signals of the assignment in the order of the publicSignals of the proof structs
+// of the contracts, which differs from the order gnark assigns the public fields for MINT and WITHDRAW, see utils.PublicOrder
+func PublicSignals(assignment frontend.Circuit) ([]string, error) {
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return nil, err
+	}
+	return utils.PublicSignals(assignment, w)
+}
+
+// an EVM address read as an integer like the contracts do with uint256(uint160(address))
+func checkAddress(address *big.Int) error {
+	if address == nil || address.Sign() < 0 || address.Cmp(maxAddress) >= 0 {
+		return errors.New("address must be a 160-bit integer")
+	}
+	return nil
+}
+
+func checkChainID(chainID *big.Int) error {
+	if chainID == nil || chainID.Sign() <= 0 || chainID.Cmp(fr.Modulus()) >= 0 {
+		return errors.New("chain id must be a positive field element")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+)
+
+// EGCTDisclosure returns the assignment of the EGCT disclosure circuit proving the ciphertext decrypts to the amount
+// with the private key, with its public signals. The amount is checked against the ciphertext before anything is built.
+func EGCTDisclosure(privateKey *big.Int, valueEGCT *babyjub.Ciphertext, amount *big.Int) (*circuits.EGCTDisclosureCircuit, []string, error) {
+	pk, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if amount == nil || amount.Sign() < 0 || amount.Cmp(babyjub.BasePointOrder) >= 0 {
+		return nil, nil, errors.New("amount must be in [0, BasePointOrder)")
+	}
+
+	decrypted := babyjub.Decrypt(privateKey, valueEGCT)
+	expected := babyjub.MulWithBasePoint(amount)
+	if !decrypted.Equal(&expected) {
+		return nil, nil, fmt.Errorf("amount %s is not the encrypted amount", amount)
+	}
+
+	assignment := &circuits.EGCTDisclosureCircuit{
+		Holder:    circuits.Holder{PrivateKey: privateKey, PublicKey: publicKey(&pk)},
+		ValueEGCT: elGamalCiphertext(valueEGCT),
+		Amount:    amount,
+	}
+
+	publicSignals, err := PublicSignals(assignment)
+	if err != nil {
+		return nil, nil, err
+	}
+	return assignment, publicSignals, nil
+}
+
+// PCTDisclosure returns the assignment of the PCT disclosure circuit proving the PCT decrypts with the private key,
+// with its public signals and the decrypted amount
+func PCTDisclosure(privateKey *big.Int, pct *poseidon.PCT) (*circuits.PCTDisclosureCircuit, []string, *big.Int, error) {
+	pk, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(pct.Ciphertext) != 4 {
+		return nil, nil, nil, errors.New("the PCT must encrypt a single value")
+	}
+
+	amount, err := pct.Decrypt(privateKey, 1)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("not encrypted for the key or altered: %w", err)
+	}
+
+	assignment := &circuits.PCTDisclosureCircuit{
+		Holder: circuits.Holder{PrivateKey: privateKey, PublicKey: publicKey(&pk)},
+		PCT:    emittedPCT(pct),
+		Amount: amount[0],
+	}
+
+	publicSignals, err := PublicSignals(assignment)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return assignment, publicSignals, amount[0], nil
+}
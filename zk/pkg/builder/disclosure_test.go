@@ -0,0 +1,128 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+)
+
+var (
+	holderKey = big.NewInt(1234)
+	otherKey  = big.NewInt(5678)
+)
+
+func testPCT(t *testing.T, privateKey *big.Int, amount int64) *poseidon.PCT {
+	t.Helper()
+	pk, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pct, _, err := poseidon.NewPCT(&pk, []*big.Int{big.NewInt(amount)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pct
+}
+
+func TestEGCTDisclosure(t *testing.T) {
+	assignment, signals, err := EGCTDisclosure(holderKey, testBalance(t, 1234, 500), big.NewInt(500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := test.IsSolved(&circuits.EGCTDisclosureCircuit{}, assignment, ecc.BN254.ScalarField()); err != nil {
+		t.Fatalf("expected the assignment to satisfy the circuit: %v", err)
+	}
+	if len(signals) != 7 || signals[6] != "500" {
+		t.Fatalf("expected the amount as the last of 7 public signals, got %v", signals)
+	}
+
+	other, err := babyjub.PublicKey(otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		modify func(*circuits.EGCTDisclosureCircuit)
+	}{
+		{"wrong amount", func(c *circuits.EGCTDisclosureCircuit) { c.Amount = 501 }},
+		{"wrong private key", func(c *circuits.EGCTDisclosureCircuit) { c.Holder.PrivateKey = otherKey }},
+		{"key pair of another holder", func(c *circuits.EGCTDisclosureCircuit) {
+			c.Holder = circuits.Holder{PrivateKey: otherKey, PublicKey: publicKey(&other)}
+		}},
+		{"EGCT of another holder", func(c *circuits.EGCTDisclosureCircuit) {
+			c.ValueEGCT = elGamalCiphertext(testBalance(t, 5678, 500))
+		}},
+	}
+	for _, tt := range tests {
+		assignment, _, err := EGCTDisclosure(holderKey, testBalance(t, 1234, 500), big.NewInt(500))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.modify(assignment)
+		if err := test.IsSolved(&circuits.EGCTDisclosureCircuit{}, assignment, ecc.BN254.ScalarField()); err == nil {
+			t.Errorf("%s: expected the circuit not to be satisfied", tt.name)
+		}
+	}
+
+	// the builder checks the amount and the key before building
+	if _, _, err := EGCTDisclosure(holderKey, testBalance(t, 1234, 500), big.NewInt(501)); err == nil {
+		t.Error("expected an error for a wrong amount")
+	}
+	if _, _, err := EGCTDisclosure(otherKey, testBalance(t, 1234, 500), big.NewInt(500)); err == nil {
+		t.Error("expected an error for a wrong key")
+	}
+}
+
+func TestPCTDisclosure(t *testing.T) {
+	assignment, signals, amount, err := PCTDisclosure(holderKey, testPCT(t, holderKey, 500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amount.Int64() != 500 {
+		t.Fatalf("expected amount 500, got %s", amount)
+	}
+	if err := test.IsSolved(&circuits.PCTDisclosureCircuit{}, assignment, ecc.BN254.ScalarField()); err != nil {
+		t.Fatalf("expected the assignment to satisfy the circuit: %v", err)
+	}
+	if len(signals) != 10 || signals[9] != "500" {
+		t.Fatalf("expected the amount as the last of 10 public signals, got %v", signals)
+	}
+
+	other, err := babyjub.PublicKey(otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		modify func(*circuits.PCTDisclosureCircuit)
+	}{
+		{"wrong amount", func(c *circuits.PCTDisclosureCircuit) { c.Amount = 501 }},
+		{"wrong private key", func(c *circuits.PCTDisclosureCircuit) { c.Holder.PrivateKey = otherKey }},
+		{"key pair of another holder", func(c *circuits.PCTDisclosureCircuit) {
+			c.Holder = circuits.Holder{PrivateKey: otherKey, PublicKey: publicKey(&other)}
+		}},
+		{"PCT of another holder", func(c *circuits.PCTDisclosureCircuit) { c.PCT = emittedPCT(testPCT(t, otherKey, 500)) }},
+		{"altered ciphertext", func(c *circuits.PCTDisclosureCircuit) { c.PCT.Ciphertext[0] = 1 }},
+		{"altered nonce", func(c *circuits.PCTDisclosureCircuit) { c.PCT.Nonce = 1 }},
+	}
+	for _, tt := range tests {
+		assignment, _, _, err := PCTDisclosure(holderKey, testPCT(t, holderKey, 500))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.modify(assignment)
+		if err := test.IsSolved(&circuits.PCTDisclosureCircuit{}, assignment, ecc.BN254.ScalarField()); err == nil {
+			t.Errorf("%s: expected the circuit not to be satisfied", tt.name)
+		}
+	}
+
+	// the PCT of another holder does not decrypt with the key
+	if _, _, _, err := PCTDisclosure(holderKey, testPCT(t, otherKey, 500)); err == nil {
+		t.Error("expected an error for the PCT of another holder")
+	}
+}
@@ -0,0 +1,59 @@
+package builder
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+)
+
+// NullifierHash returns Poseidon(chainID, auditorPCT...), the hash CheckNullifierHash expects
+// and the contract records to reject a mint proof submitted twice
+func NullifierHash(chainID *big.Int, auditorPCT []*big.Int) (*big.Int, error) {
+	return poseidon.Hash(append([]*big.Int{chainID}, auditorPCT...)...)
+}
+
+// Mint returns the assignment of the mint circuit minting the amount to the receiver with its public signals
+// in the MintProof order (chain id and nullifier hash first). The amount must be in [0, BasePointOrder),
+// the range the receiver's ciphertext can encode. The randomness of the ciphertexts is sampled from random (crypto/rand if nil).
+//
+// The nullifier hashes the auditor PCT, which is encrypted with a fresh randomness and nonce on every call,
+// so two mints never share a nullifier as long as the random source is never replayed.
+func Mint(receiver, auditor *babyjub.Point, amount, chainID *big.Int, random io.Reader) (*circuits.MintCircuit, []string, error) {
+	if amount == nil || amount.Sign() < 0 || amount.Cmp(babyjub.BasePointOrder) >= 0 {
+		return nil, nil, errors.New("amount must be in [0, BasePointOrder)")
+	}
+	if err := checkChainID(chainID); err != nil {
+		return nil, nil, err
+	}
+
+	r, err := newReceiver(receiver, amount, random)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, auditorPCT, err := newAuditor(auditor, amount, random)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nullifier, err := NullifierHash(chainID, auditorPCT.Ciphertext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assignment := &circuits.MintCircuit{
+		Receiver:      r,
+		Auditor:       a,
+		MintNullifier: circuits.MintNullifier{ChainID: chainID, NullifierHash: nullifier},
+		ValueToMint:   amount,
+	}
+
+	publicSignals, err := PublicSignals(assignment)
+	if err != nil {
+		return nil, nil, err
+	}
+	return assignment, publicSignals, nil
+}
@@ -0,0 +1,45 @@
+package builder
+
+import (
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+)
+
+// RegistrationHash returns Poseidon(chainID, privateKey, address), the hash CheckRegistrationHash expects
+// and the Registrar stores to prevent replaying a registration proof
+func RegistrationHash(chainID, privateKey, address *big.Int) (*big.Int, error) {
+	return poseidon.Hash(chainID, privateKey, address)
+}
+
+// Registration returns the assignment of the registration circuit for the babyjub private key,
+// the EVM address registering it and the chain id of the Registrar
+func Registration(privateKey, address, chainID *big.Int) (*circuits.RegistrationCircuit, error) {
+	pk, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAddress(address); err != nil {
+		return nil, err
+	}
+	if err := checkChainID(chainID); err != nil {
+		return nil, err
+	}
+
+	hash, err := RegistrationHash(chainID, privateKey, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &circuits.RegistrationCircuit{
+		Sender: circuits.RegistrationSender{
+			PrivateKey:       privateKey,
+			PublicKey:        publicKey(&pk),
+			Address:          address,
+			ChainID:          chainID,
+			RegistrationHash: hash,
+		},
+	}, nil
+}
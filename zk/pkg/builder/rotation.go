@@ -0,0 +1,47 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+)
+
+// AuditorRotation returns the assignment of the auditor rotation circuit re-encrypting the auditor PCT, emitted for
+// the old auditor, for the new auditor with its public signals and the new PCT. The PCT must decrypt with the old
+// auditor's private key, the randomness of the new PCT is sampled from random (crypto/rand if nil).
+func AuditorRotation(oldPrivateKey *big.Int, pct *poseidon.PCT, newAuditorKey *babyjub.Point, random io.Reader) (*circuits.AuditorRotationCircuit, []string, *poseidon.PCT, error) {
+	oldPublicKey, err := babyjub.PublicKey(oldPrivateKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(pct.Ciphertext) != 4 {
+		return nil, nil, nil, errors.New("the auditor PCT must encrypt a single value")
+	}
+
+	value, err := pct.Decrypt(oldPrivateKey, 1)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("not encrypted for the old auditor key or altered: %w", err)
+	}
+
+	a, newPCT, err := newAuditor(newAuditorKey, value[0], random)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	assignment := &circuits.AuditorRotationCircuit{
+		OldAuditor: circuits.OutgoingAuditor{PrivateKey: oldPrivateKey, PublicKey: publicKey(&oldPublicKey), PCT: emittedPCT(pct)},
+		NewAuditor: a,
+		Value:      value[0],
+	}
+
+	publicSignals, err := PublicSignals(assignment)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return assignment, publicSignals, newPCT, nil
+}
@@ -0,0 +1,67 @@
+package builder
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// MaxBalance is the upper bound of a threshold proof that only claims a minimum, CheckBalance already
+// bounds the balance by it and CheckUpperBound refuses a larger upper bound
+var MaxBalance = new(big.Int).Sub(babyjub.BasePointOrder, big.NewInt(1))
+
+// BalanceThreshold returns the assignment of the balance threshold circuit proving the balance encrypted in the
+// balance EGCT is in [threshold, upperBound] with its public signals. The balance is the plaintext of the EGCT,
+// the upper bound defaults to MaxBalance when nil. The challenge is required, it is the one the verifier chose.
+func BalanceThreshold(privateKey *big.Int, balanceEGCT *babyjub.Ciphertext, balance, threshold, upperBound, challenge *big.Int) (*circuits.BalanceThresholdCircuit, []string, error) {
+	if upperBound == nil {
+		upperBound = MaxBalance
+	}
+	if challenge == nil {
+		return nil, nil, errors.New("challenge is required, it is chosen by the verifier")
+	}
+	if threshold == nil || threshold.Sign() < 0 || threshold.Cmp(upperBound) > 0 {
+		return nil, nil, errors.New("threshold must be in [0, upperBound]")
+	}
+	if upperBound.Cmp(MaxBalance) > 0 {
+		return nil, nil, errors.New("upper bound must be at most BasePointOrder - 1")
+	}
+	if challenge.Sign() < 0 || challenge.Cmp(fr.Modulus()) >= 0 {
+		return nil, nil, errors.New("challenge must be a field element")
+	}
+
+	pk, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := CheckBalance(privateKey, balanceEGCT, balance); err != nil {
+		return nil, nil, err
+	}
+	if balance.Cmp(threshold) < 0 {
+		return nil, nil, errors.New("balance is below the threshold")
+	}
+	if balance.Cmp(upperBound) > 0 {
+		return nil, nil, errors.New("balance is above the upper bound")
+	}
+
+	assignment := &circuits.BalanceThresholdCircuit{
+		Sender: circuits.WithdrawSender{
+			PrivateKey:  privateKey,
+			PublicKey:   publicKey(&pk),
+			Balance:     balance,
+			BalanceEGCT: elGamalCiphertext(balanceEGCT),
+		},
+		Threshold:  threshold,
+		UpperBound: upperBound,
+		Challenge:  challenge,
+	}
+
+	publicSignals, err := PublicSignals(assignment)
+	if err != nil {
+		return nil, nil, err
+	}
+	return assignment, publicSignals, nil
+}
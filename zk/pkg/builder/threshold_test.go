@@ -0,0 +1,147 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/test"
+)
+
+// encrypts the balance under the public key of the private key with a fixed randomness
+func testBalance(t *testing.T, privateKey, balance int64) *babyjub.Ciphertext {
+	t.Helper()
+	pk, err := babyjub.PublicKey(big.NewInt(privateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := babyjub.Encrypt(&pk, big.NewInt(balance), big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ct
+}
+
+func testThreshold(t *testing.T) *circuits.BalanceThresholdCircuit {
+	t.Helper()
+	assignment, _, err := BalanceThreshold(big.NewInt(1234), testBalance(t, 1234, 500), big.NewInt(500), big.NewInt(100), big.NewInt(1000), big.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return assignment
+}
+
+func TestBalanceThreshold(t *testing.T) {
+	assignment := testThreshold(t)
+	if err := test.IsSolved(&circuits.BalanceThresholdCircuit{}, assignment, ecc.BN254.ScalarField()); err != nil {
+		t.Fatalf("expected the assignment to satisfy the circuit: %v", err)
+	}
+
+	// the threshold and the upper bound are inclusive, the default upper bound is MaxBalance
+	assignment, _, err := BalanceThreshold(big.NewInt(1234), testBalance(t, 1234, 500), big.NewInt(500), big.NewInt(500), nil, big.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := test.IsSolved(&circuits.BalanceThresholdCircuit{}, assignment, ecc.BN254.ScalarField()); err != nil {
+		t.Fatalf("expected the assignment to satisfy the circuit: %v", err)
+	}
+}
+
+func TestBalanceThresholdUnsatisfied(t *testing.T) {
+	other, err := babyjub.PublicKey(big.NewInt(5678))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*circuits.BalanceThresholdCircuit)
+	}{
+		{"threshold above the balance", func(c *circuits.BalanceThresholdCircuit) { c.Threshold = 501 }},
+		{"balance above the upper bound", func(c *circuits.BalanceThresholdCircuit) { c.UpperBound = 499 }},
+		{"upper bound above BasePointOrder - 1", func(c *circuits.BalanceThresholdCircuit) { c.UpperBound = babyjub.BasePointOrder }},
+		{"balance not the encrypted one", func(c *circuits.BalanceThresholdCircuit) { c.Sender.Balance = 600 }},
+		{"balance encrypted for another key", func(c *circuits.BalanceThresholdCircuit) {
+			ct, err := babyjub.Encrypt(&other, big.NewInt(500), big.NewInt(42))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Sender.BalanceEGCT = elGamalCiphertext(&ct)
+		}},
+		{"public key of another key", func(c *circuits.BalanceThresholdCircuit) { c.Sender.PublicKey = publicKey(&other) }},
+	}
+
+	for _, tt := range tests {
+		assignment := testThreshold(t)
+		tt.modify(assignment)
+		if err := test.IsSolved(&circuits.BalanceThresholdCircuit{}, assignment, ecc.BN254.ScalarField()); err == nil {
+			t.Errorf("%s: expected the circuit not to be satisfied", tt.name)
+		}
+	}
+}
+
+func TestBalanceThresholdRejects(t *testing.T) {
+	balance := testBalance(t, 1234, 500)
+	tests := []struct {
+		name                                       string
+		privateKey, balance, threshold, upperBound *big.Int
+		challenge                                  *big.Int
+	}{
+		{"threshold above the balance", big.NewInt(1234), big.NewInt(500), big.NewInt(501), nil, big.NewInt(7)},
+		{"balance above the upper bound", big.NewInt(1234), big.NewInt(500), big.NewInt(100), big.NewInt(499), big.NewInt(7)},
+		{"upper bound above MaxBalance", big.NewInt(1234), big.NewInt(500), big.NewInt(100), babyjub.BasePointOrder, big.NewInt(7)},
+		{"wrong private key", big.NewInt(5678), big.NewInt(500), big.NewInt(100), nil, big.NewInt(7)},
+		{"balance not the encrypted one", big.NewInt(1234), big.NewInt(600), big.NewInt(100), nil, big.NewInt(7)},
+		{"missing challenge", big.NewInt(1234), big.NewInt(500), big.NewInt(100), nil, nil},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := BalanceThreshold(tt.privateKey, balance, tt.balance, tt.threshold, tt.upperBound, tt.challenge); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+// a proof for one challenge does not verify for another, CheckChallenge binds it
+func TestBalanceThresholdChallenge(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuits.BalanceThresholdCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assignment := testThreshold(t)
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := groth16.Prove(ccs, pk, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	public, err := w.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := groth16.Verify(proof, vk, public); err != nil {
+		t.Fatalf("expected the proof to verify: %v", err)
+	}
+
+	assignment.Challenge = 8
+	other, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := groth16.Verify(proof, vk, other); err == nil {
+		t.Fatal("expected the proof not to verify with another challenge")
+	}
+}
@@ -0,0 +1,95 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+)
+
+// CheckBalance checks the plaintext balance is the one encrypted in the balance EGCT, like CheckBalance in the circuits
+func CheckBalance(privateKey *big.Int, balanceEGCT *babyjub.Ciphertext, balance *big.Int) error {
+	if balanceEGCT.IsZero() {
+		return errors.New("balance is not initialized")
+	}
+	if balance == nil || balance.Sign() < 0 || balance.Cmp(babyjub.BasePointOrder) >= 0 {
+		return errors.New("balance must be in [0, BasePointOrder)")
+	}
+
+	decrypted := babyjub.Decrypt(privateKey, balanceEGCT)
+	expected := babyjub.MulWithBasePoint(balance)
+	if !decrypted.Equal(&expected) {
+		return fmt.Errorf("balance %s is not the encrypted balance", balance)
+	}
+	return nil
+}
+
+// Transfer returns the assignment of the transfer circuit sending the amount from the sender to the receiver,
+// its public signals in the TransferProof order and the sender's balance PCT after the transfer,
+// the balancePCT argument of EncryptedERC.transfer. The balance is the plaintext of the sender's current
+// balance EGCT, it is checked before anything is built. The randomness is sampled from random (crypto/rand if nil).
+func Transfer(
+	privateKey *big.Int,
+	balanceEGCT *babyjub.Ciphertext,
+	balance *big.Int,
+	receiver, auditor *babyjub.Point,
+	amount *big.Int,
+	random io.Reader,
+) (*circuits.TransferCircuit, []string, *poseidon.PCT, error) {
+	senderPK, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := CheckBalance(privateKey, balanceEGCT, balance); err != nil {
+		return nil, nil, nil, err
+	}
+	if amount == nil || amount.Sign() < 0 {
+		return nil, nil, nil, errors.New("amount must not be negative")
+	}
+	if amount.Cmp(balance) > 0 {
+		return nil, nil, nil, fmt.Errorf("amount %s exceeds the balance %s", amount, balance)
+	}
+
+	// the contract subtracts the amount encrypted for the sender from the balance
+	valueEGCT, _, err := babyjub.EncryptRandom(&senderPK, amount, random)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	r, err := newReceiver(receiver, amount, random)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	a, _, err := newAuditor(auditor, amount, random)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	balancePCT, _, err := poseidon.NewPCT(&senderPK, []*big.Int{new(big.Int).Sub(balance, amount)}, random)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	assignment := &circuits.TransferCircuit{
+		Sender: circuits.Sender{
+			PrivateKey:  privateKey,
+			PublicKey:   publicKey(&senderPK),
+			Balance:     balance,
+			BalanceEGCT: elGamalCiphertext(balanceEGCT),
+			ValueEGCT:   elGamalCiphertext(&valueEGCT),
+		},
+		Receiver:        r,
+		Auditor:         a,
+		ValueToTransfer: amount,
+	}
+
+	publicSignals, err := PublicSignals(assignment)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return assignment, publicSignals, balancePCT, nil
+}
@@ -0,0 +1,197 @@
+package ceremony
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"runtime"
+	"sync"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+)
+
+// domain separation tags for deriving the beacon contributions
+var (
+	beaconDST       = []byte("eERC-phase2-beacon")
+	phase1BeaconDST = []byte("eERC-phase1-beacon")
+)
+
+// maximum beacon iterations exponent, 2^40 sha256 rounds already take hours
+const maxBeaconIterations = 40
+
+// derives n non-zero scalars from the public beacon, the beacon is first hashed 2^iterations times
+func beaconScalars(beacon []byte, iterations int, dst []byte, n int) ([]fr.Element, error) {
+	if len(beacon) == 0 {
+		return nil, errors.New("beacon value is required")
+	}
+	if iterations < 0 || iterations > maxBeaconIterations {
+		return nil, errors.New("beacon iterations must be between 0 and 40")
+	}
+
+	// delay function over the beacon so it can not be grinded at publication time
+	seed := sha256.Sum256(beacon)
+	for i := uint64(0); i < uint64(1)<<iterations; i++ {
+		seed = sha256.Sum256(seed[:])
+	}
+
+	elements, err := fr.Hash(seed[:], dst, n)
+	if err != nil {
+		return nil, err
+	}
+	for i := range elements {
+		if elements[i].IsZero() {
+			return nil, errors.New("beacon derived a zero scalar")
+		}
+	}
+	return elements, nil
+}
+
+// proof of knowledge of x with the nonce s, same construction as the unexported mpcsetup.newPublicKey
+func beaconPublicKey(x, s fr.Element, challenge []byte, dst byte) (mpcsetup.PublicKey, error) {
+	var xBI, sBI big.Int
+	x.BigInt(&xBI)
+	s.BigInt(&sBI)
+
+	var pk mpcsetup.PublicKey
+	_, _, g1, _ := curve.Generators()
+	pk.SG.ScalarMultiplication(&g1, &sBI)
+	pk.SXG.ScalarMultiplication(&pk.SG, &xBI)
+	r, err := genR(pk.SG, pk.SXG, challenge, dst)
+	if err != nil {
+		return pk, err
+	}
+	pk.XR.ScalarMultiplication(&r, &xBI)
+	return pk, nil
+}
+
+// contributeBeacon applies a contribution whose δ is derived from the public beacon,
+// it follows mpcsetup.Phase2.Contribute with the randomness replaced by the beacon
+func contributeBeacon(c *mpcsetup.Phase2, beacon []byte, iterations int) error {
+	elements, err := beaconScalars(beacon, iterations, beaconDST, 2)
+	if err != nil {
+		return err
+	}
+	delta, s := elements[0], elements[1]
+
+	var deltaInv fr.Element
+	deltaInv.Inverse(&delta)
+
+	var deltaBI, deltaInvBI big.Int
+	delta.BigInt(&deltaBI)
+	deltaInv.BigInt(&deltaInvBI)
+
+	c.PublicKey, err = beaconPublicKey(delta, s, c.Hash, 1)
+	if err != nil {
+		return err
+	}
+
+	c.Parameters.G1.Delta.ScalarMultiplication(&c.Parameters.G1.Delta, &deltaBI)
+	c.Parameters.G2.Delta.ScalarMultiplication(&c.Parameters.G2.Delta, &deltaBI)
+	for i := range c.Parameters.G1.Z {
+		c.Parameters.G1.Z[i].ScalarMultiplication(&c.Parameters.G1.Z[i], &deltaInvBI)
+	}
+	for i := range c.Parameters.G1.L {
+		c.Parameters.G1.L[i].ScalarMultiplication(&c.Parameters.G1.L[i], &deltaInvBI)
+	}
+
+	c.Hash = hashPhase2(c)
+	return nil
+}
+
+// contributePhase1Beacon applies a contribution whose τ, α and β are derived from the public beacon,
+// it follows mpcsetup.Phase1.Contribute with the randomness replaced by the beacon
+func contributePhase1Beacon(c *mpcsetup.Phase1, beacon []byte, iterations int) error {
+	elements, err := beaconScalars(beacon, iterations, phase1BeaconDST, 6)
+	if err != nil {
+		return err
+	}
+	tau, alpha, beta := elements[0], elements[1], elements[2]
+
+	if c.PublicKeys.Tau, err = beaconPublicKey(tau, elements[3], c.Hash, 1); err != nil {
+		return err
+	}
+	if c.PublicKeys.Alpha, err = beaconPublicKey(alpha, elements[4], c.Hash, 2); err != nil {
+		return err
+	}
+	if c.PublicKeys.Beta, err = beaconPublicKey(beta, elements[5], c.Hash, 3); err != nil {
+		return err
+	}
+
+	// [τⁱ]₁ for i < 2n-1, [τⁱ]₂, α[τⁱ]₁ and β[τⁱ]₁ for i < n
+	n := len(c.Parameters.G2.Tau)
+	taus := make([]fr.Element, 2*n-1)
+	taus[0].SetOne()
+	for i := 1; i < len(taus); i++ {
+		taus[i].Mul(&taus[i-1], &tau)
+	}
+	parallelize(len(taus), func(start, end int) {
+		var tauBI, alphaTauBI, betaTauBI big.Int
+		var alphaTau, betaTau fr.Element
+		for i := start; i < end; i++ {
+			taus[i].BigInt(&tauBI)
+			c.Parameters.G1.Tau[i].ScalarMultiplication(&c.Parameters.G1.Tau[i], &tauBI)
+			if i >= n {
+				continue
+			}
+			alphaTau.Mul(&taus[i], &alpha).BigInt(&alphaTauBI)
+			betaTau.Mul(&taus[i], &beta).BigInt(&betaTauBI)
+			c.Parameters.G2.Tau[i].ScalarMultiplication(&c.Parameters.G2.Tau[i], &tauBI)
+			c.Parameters.G1.AlphaTau[i].ScalarMultiplication(&c.Parameters.G1.AlphaTau[i], &alphaTauBI)
+			c.Parameters.G1.BetaTau[i].ScalarMultiplication(&c.Parameters.G1.BetaTau[i], &betaTauBI)
+		}
+	})
+	var betaBI big.Int
+	beta.BigInt(&betaBI)
+	c.Parameters.G2.Beta.ScalarMultiplication(&c.Parameters.G2.Beta, &betaBI)
+
+	c.Hash = hashPhase1(c)
+	return nil
+}
+
+// runs f over [0, n) split in one range per CPU
+func parallelize(n int, f func(start, end int)) {
+	nbTasks := runtime.NumCPU()
+	chunk := (n + nbTasks - 1) / nbTasks
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			f(start, end)
+		}(start, min(start+chunk, n))
+	}
+	wg.Wait()
+}
+
+// R in G₂ as Hash(gˢ, gˢˣ, challenge, dst), mirrors the unexported mpcsetup.genR
+func genR(sG1, sxG1 curve.G1Affine, challenge []byte, dst byte) (curve.G2Affine, error) {
+	buf := make([]byte, 0, len(challenge)+curve.SizeOfG1AffineUncompressed*2)
+	buf = append(buf, sG1.Marshal()...)
+	buf = append(buf, sxG1.Marshal()...)
+	buf = append(buf, challenge...)
+	return curve.HashToG2(buf, []byte{dst})
+}
+
+// hash of the contribution without its own hash, mirrors the unexported mpcsetup.Phase2.hash
+func hashPhase2(c *mpcsetup.Phase2) []byte {
+	unhashed := *c
+	unhashed.Hash = nil
+	h := sha256.New()
+	if _, err := unhashed.WriteTo(h); err != nil {
+		return nil
+	}
+	return h.Sum(nil)
+}
+
+// hash of the contribution without its own hash, mirrors the unexported mpcsetup.Phase1.hash
+func hashPhase1(c *mpcsetup.Phase1) []byte {
+	unhashed := *c
+	unhashed.Hash = nil
+	h := sha256.New()
+	if _, err := unhashed.WriteTo(h); err != nil {
+		return nil
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,466 @@
+package ceremony
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	cs "github.com/consensys/gnark/constraint/bn254"
+)
+
+/*
+	Phase-2 ceremony of a single circuit, all state lives in one directory that is passed between participants
+
+	<NAME>.r1cs               compiled circuit the ceremony is bound to
+	<NAME>.phase1             final SRS of the phase-1 ceremony (see phase1.go) truncated to the circuit, verified once by Init
+	<NAME>.phase2.0000        initial parameters derived from the phase-1 SRS
+	<NAME>.phase2.0001 ...    one file per contribution, the beacon is the last one
+	<NAME>.transcript.json    hashes of every step, used to verify the ceremony
+*/
+
+// Transcript records every step of the ceremony
+type Transcript struct {
+	Circuit       string         `json:"circuit"`
+	R1CS          string         `json:"r1csSha256"`
+	NbConstraints int            `json:"nbConstraints"`
+	Phase1        Phase1Info     `json:"phase1"`
+	Contributions []Contribution `json:"contributions"`
+	Beacon        *Beacon        `json:"beacon,omitempty"`
+	Keys          *Keys          `json:"keys,omitempty"`
+}
+
+// Phase1Info binds the ceremony to the final contribution of a verified phase-1 ceremony
+type Phase1Info struct {
+	// hash of the final phase-1 contribution, as recorded in the phase-1 transcript
+	Sha256 string `json:"sha256"`
+	// sha256 of the .ptau file the phase-1 SRS was imported from, if any
+	Ptau string `json:"ptauSha256,omitempty"`
+	// sha256 of <NAME>.phase1, the SRS truncated to the circuit
+	SRS string `json:"srsSha256"`
+	// number of powers of τ used by the ceremony
+	Size int `json:"size"`
+}
+
+// Contribution is one step of the ceremony, index 0 is the initial state
+type Contribution struct {
+	Index       int       `json:"index"`
+	Participant string    `json:"participant"`
+	File        string    `json:"file"`
+	Hash        string    `json:"hash"`
+	Time        time.Time `json:"time"`
+}
+
+// Beacon is the final public randomness applied to the parameters
+type Beacon struct {
+	Value string `json:"value"`
+	// the beacon is hashed 2^Iterations times before deriving the contribution
+	Iterations int `json:"iterations"`
+	Index      int `json:"index"`
+}
+
+type Keys struct {
+	ProvingKey   string `json:"pkSha256"`
+	VerifyingKey string `json:"vkSha256"`
+}
+
+func transcriptPath(dir, name string) string { return filepath.Join(dir, name+".transcript.json") }
+func r1csPath(dir, name string) string       { return filepath.Join(dir, name+".r1cs") }
+func srsPath(dir, name string) string        { return filepath.Join(dir, name+".phase1") }
+func phase2File(name string, index int) string {
+	return fmt.Sprintf("%s.phase2.%04d", name, index)
+}
+
+// Init starts the ceremony of the circuit from the phase-1 ceremony in phase1Dir, it is verified and its SRS is
+// truncated to the circuit size and kept next to the transcript
+func Init(dir, name string, ccs *cs.R1CS, phase1Dir string) (*Transcript, error) {
+	if _, err := os.Stat(transcriptPath(dir, name)); err == nil {
+		return nil, fmt.Errorf("ceremony for %s already exists in %s", name, dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	srs1, info, err := readPhase1(phase1Dir, ccs.GetNbConstraints())
+	if err != nil {
+		return nil, err
+	}
+	var bufSRS bytes.Buffer
+	if _, err := srs1.WriteTo(&bufSRS); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(srsPath(dir, name), bufSRS.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	info.SRS = sha256Hex(bufSRS.Bytes())
+
+	var bufCS bytes.Buffer
+	if _, err := ccs.WriteTo(&bufCS); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(r1csPath(dir, name), bufCS.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+
+	srs2, _ := mpcsetup.InitPhase2(ccs, srs1)
+
+	file := phase2File(name, 0)
+	if err := writeTo(filepath.Join(dir, file), &srs2); err != nil {
+		return nil, err
+	}
+
+	t := &Transcript{
+		Circuit:       name,
+		R1CS:          sha256Hex(bufCS.Bytes()),
+		NbConstraints: ccs.GetNbConstraints(),
+		Phase1:        *info,
+		Contributions: []Contribution{{Index: 0, Participant: "init", File: file, Hash: hex.EncodeToString(srs2.Hash), Time: time.Now().UTC()}},
+	}
+	return t, t.save(dir)
+}
+
+// Contribute adds fresh randomness on top of the last contribution, the toxic waste never leaves this function
+func Contribute(dir, name, participant string) (*Contribution, error) {
+	t, err := LoadTranscript(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if t.Beacon != nil {
+		return nil, errors.New("the beacon has been applied, no more contributions are accepted")
+	}
+
+	prev, err := t.readContribution(dir, len(t.Contributions)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	next := clone(prev)
+	next.Contribute()
+
+	if err := mpcsetup.VerifyPhase2(prev, next); err != nil {
+		return nil, fmt.Errorf("verifying own contribution: %w", err)
+	}
+
+	return t.append(dir, name, participant, next)
+}
+
+// ApplyBeacon applies the public random beacon as the last contribution, anyone can recompute it
+func ApplyBeacon(dir, name string, beacon []byte, iterations int) (*Contribution, error) {
+	t, err := LoadTranscript(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if t.Beacon != nil {
+		return nil, errors.New("the beacon has already been applied")
+	}
+	if len(t.Contributions) < 2 {
+		return nil, errors.New("at least one contribution is required before the beacon")
+	}
+
+	prev, err := t.readContribution(dir, len(t.Contributions)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	next := clone(prev)
+	if err := contributeBeacon(next, beacon, iterations); err != nil {
+		return nil, err
+	}
+
+	t.Beacon = &Beacon{Value: hex.EncodeToString(beacon), Iterations: iterations, Index: len(t.Contributions)}
+	return t.append(dir, name, "beacon", next)
+}
+
+// Verify checks the whole ceremony: the circuit, the phase-1 SRS, the initial parameters, every contribution,
+// the beacon and, if already extracted, the keys. The phase-1 SRS is checked against the hash recorded by Init,
+// given phase1Dir the phase-1 ceremony is verified again and must truncate to the same SRS.
+func Verify(dir, name, phase1Dir string) error {
+	t, err := LoadTranscript(dir, name)
+	if err != nil {
+		return err
+	}
+
+	ccs, srs1, err := t.readInputs(dir, name)
+	if err != nil {
+		return err
+	}
+
+	if len(phase1Dir) != 0 {
+		srs, info, err := readPhase1(phase1Dir, ccs.GetNbConstraints())
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if _, err := srs.WriteTo(&buf); err != nil {
+			return err
+		}
+		if info.Sha256 != t.Phase1.Sha256 || sha256Hex(buf.Bytes()) != t.Phase1.SRS {
+			return errors.New("phase-1 ceremony does not match the transcript")
+		}
+	}
+
+	_, _, err = t.verify(dir, ccs, srs1)
+	return err
+}
+
+// Finalize verifies the ceremony and extracts the proving and verifying keys from the last contribution
+func Finalize(dir, name string) (*cs.R1CS, *groth16_bn254.ProvingKey, *groth16_bn254.VerifyingKey, error) {
+	t, err := LoadTranscript(dir, name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if t.Beacon == nil {
+		return nil, nil, nil, errors.New("the beacon has not been applied")
+	}
+
+	ccs, srs1, err := t.readInputs(dir, name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	last, evals, err := t.verify(dir, ccs, srs1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	pk, vk := extractKeys(ccs, srs1, last, evals)
+
+	keys, err := hashKeys(pk, vk)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	t.Keys = keys
+
+	return ccs, pk, vk, t.save(dir)
+}
+
+// verifies the chain of contributions, returns the last one and the evaluations needed to extract the keys
+func (t *Transcript) verify(dir string, ccs *cs.R1CS, srs1 *mpcsetup.Phase1) (*mpcsetup.Phase2, *mpcsetup.Phase2Evaluations, error) {
+	// the initial parameters are deterministic, only the proof of knowledge of δ=1 is random
+	initial, err := t.readContribution(dir, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	expected, evals := mpcsetup.InitPhase2(ccs, srs1)
+	if !bytes.Equal(parametersDigest(initial), parametersDigest(&expected)) {
+		return nil, nil, errors.New("initial parameters do not match the circuit and the phase-1 SRS")
+	}
+
+	prev := initial
+	for i := 1; i < len(t.Contributions); i++ {
+		next, err := t.readContribution(dir, i)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := mpcsetup.VerifyPhase2(prev, next); err != nil {
+			return nil, nil, fmt.Errorf("contribution %d by %s: %w", i, t.Contributions[i].Participant, err)
+		}
+
+		if t.Beacon != nil && t.Beacon.Index == i {
+			beacon, err := hex.DecodeString(t.Beacon.Value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("beacon value: %w", err)
+			}
+			recomputed := clone(prev)
+			if err := contributeBeacon(recomputed, beacon, t.Beacon.Iterations); err != nil {
+				return nil, nil, err
+			}
+			if !bytes.Equal(recomputed.Hash, next.Hash) {
+				return nil, nil, errors.New("beacon contribution does not match the beacon value")
+			}
+		}
+		prev = next
+	}
+
+	if t.Beacon != nil && t.Beacon.Index != len(t.Contributions)-1 {
+		return nil, nil, errors.New("the beacon must be the last contribution")
+	}
+
+	if t.Keys != nil {
+		keys, err := hashKeys(extractKeys(ccs, srs1, prev, &evals))
+		if err != nil {
+			return nil, nil, err
+		}
+		if *keys != *t.Keys {
+			return nil, nil, errors.New("extracted keys do not match the transcript")
+		}
+	}
+
+	return prev, &evals, nil
+}
+
+// reads the compiled circuit and the truncated phase-1 SRS and checks them against the transcript
+func (t *Transcript) readInputs(dir, name string) (*cs.R1CS, *mpcsetup.Phase1, error) {
+	csBytes, err := os.ReadFile(r1csPath(dir, name))
+	if err != nil {
+		return nil, nil, err
+	}
+	if sha256Hex(csBytes) != t.R1CS {
+		return nil, nil, errors.New("constraint system does not match the transcript")
+	}
+	ccs := &cs.R1CS{}
+	if _, err := ccs.ReadFrom(bytes.NewReader(csBytes)); err != nil {
+		return nil, nil, err
+	}
+
+	srsBytes, err := os.ReadFile(srsPath(dir, name))
+	if err != nil {
+		return nil, nil, err
+	}
+	if sha256Hex(srsBytes) != t.Phase1.SRS {
+		return nil, nil, errors.New("phase-1 SRS does not match the transcript")
+	}
+	srs1 := &mpcsetup.Phase1{}
+	if _, err := srs1.ReadFrom(bytes.NewReader(srsBytes)); err != nil {
+		return nil, nil, err
+	}
+	return ccs, srs1, nil
+}
+
+// reads the contribution at index and checks its hash against the transcript
+func (t *Transcript) readContribution(dir string, index int) (*mpcsetup.Phase2, error) {
+	c := t.Contributions[index]
+
+	var p mpcsetup.Phase2
+	if err := readFrom(filepath.Join(dir, c.File), &p); err != nil {
+		return nil, err
+	}
+	if hex.EncodeToString(p.Hash) != c.Hash {
+		return nil, fmt.Errorf("contribution %d does not match the transcript", index)
+	}
+	return &p, nil
+}
+
+func (t *Transcript) append(dir, name, participant string, p *mpcsetup.Phase2) (*Contribution, error) {
+	index := len(t.Contributions)
+	file := phase2File(name, index)
+	if err := writeTo(filepath.Join(dir, file), p); err != nil {
+		return nil, err
+	}
+
+	c := Contribution{Index: index, Participant: participant, File: file, Hash: hex.EncodeToString(p.Hash), Time: time.Now().UTC()}
+	t.Contributions = append(t.Contributions, c)
+	return &c, t.save(dir)
+}
+
+func (t *Transcript) save(dir string) error {
+	out, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(transcriptPath(dir, t.Circuit), out, 0644)
+}
+
+func LoadTranscript(dir, name string) (*Transcript, error) {
+	in, err := os.ReadFile(transcriptPath(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	var t Transcript
+	if err := json.Unmarshal(in, &t); err != nil {
+		return nil, fmt.Errorf("parsing transcript: %w", err)
+	}
+	if len(t.Contributions) == 0 {
+		return nil, errors.New("transcript has no initial contribution")
+	}
+	return &t, nil
+}
+
+// the evaluations are not fully serialized by gnark, they are recomputed from the circuit and the phase-1 SRS
+func extractKeys(ccs *cs.R1CS, srs1 *mpcsetup.Phase1, srs2 *mpcsetup.Phase2, evals *mpcsetup.Phase2Evaluations) (*groth16_bn254.ProvingKey, *groth16_bn254.VerifyingKey) {
+	pk, vk := mpcsetup.ExtractKeys(srs1, clone(srs2), evals, ccs.GetNbConstraints())
+	return &pk, &vk
+}
+
+func hashKeys(pk *groth16_bn254.ProvingKey, vk *groth16_bn254.VerifyingKey) (*Keys, error) {
+	var bufPK, bufVK bytes.Buffer
+	if _, err := pk.WriteTo(&bufPK); err != nil {
+		return nil, err
+	}
+	if _, err := vk.WriteTo(&bufVK); err != nil {
+		return nil, err
+	}
+	return &Keys{ProvingKey: sha256Hex(bufPK.Bytes()), VerifyingKey: sha256Hex(bufVK.Bytes())}, nil
+}
+
+// verifies the phase-1 ceremony in the directory and truncates its final SRS to the evaluation domain of the circuit,
+// the returned info holds the hash of the final contribution and of the imported .ptau file
+func readPhase1(phase1Dir string, nbConstraints int) (*mpcsetup.Phase1, *Phase1Info, error) {
+	t, err := LoadPhase1Transcript(phase1Dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading phase-1 ceremony: %w", err)
+	}
+	if !t.Final() {
+		return nil, nil, errors.New("the beacon has not been applied to the phase-1 ceremony")
+	}
+	srs1, err := t.verify(phase1Dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("verifying phase-1 ceremony: %w", err)
+	}
+
+	n := int(fft.NewDomain(uint64(nbConstraints)).Cardinality)
+	if len(srs1.Parameters.G1.AlphaTau) < n || len(srs1.Parameters.G1.Tau) < 2*n-1 {
+		return nil, nil, fmt.Errorf("phase-1 SRS supports %d constraints, circuit needs %d", len(srs1.Parameters.G1.AlphaTau), n)
+	}
+	info := &Phase1Info{Sha256: hex.EncodeToString(srs1.Hash), Size: n}
+	if t.Import != nil {
+		info.Ptau = t.Import.Sha256
+	}
+	srs1.Parameters.G1.Tau = srs1.Parameters.G1.Tau[:2*n-1]
+	srs1.Parameters.G1.AlphaTau = srs1.Parameters.G1.AlphaTau[:n]
+	srs1.Parameters.G1.BetaTau = srs1.Parameters.G1.BetaTau[:n]
+	srs1.Parameters.G2.Tau = srs1.Parameters.G2.Tau[:n]
+
+	return srs1, info, nil
+}
+
+// digest of the parameters without the proof of knowledge
+func parametersDigest(p *mpcsetup.Phase2) []byte {
+	c := *p
+	c.PublicKey = mpcsetup.PublicKey{}
+	c.Hash = nil
+	h := sha256.New()
+	if _, err := c.WriteTo(h); err != nil {
+		return nil
+	}
+	return h.Sum(nil)
+}
+
+func clone(p *mpcsetup.Phase2) *mpcsetup.Phase2 {
+	c := *p
+	c.Parameters.G1.L = append(c.Parameters.G1.L[:0:0], p.Parameters.G1.L...)
+	c.Parameters.G1.Z = append(c.Parameters.G1.Z[:0:0], p.Parameters.G1.Z...)
+	c.Hash = append(c.Hash[:0:0], p.Hash...)
+	return &c
+}
+
+func writeTo(path string, w io.WriterTo) error {
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func readFrom(path string, r io.ReaderFrom) error {
+	in, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	_, err = r.ReadFrom(bytes.NewReader(in))
+	return err
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
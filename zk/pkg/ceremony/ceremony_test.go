@@ -0,0 +1,231 @@
+package ceremony
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// X³ + X + 5 = Y
+type toyCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *toyCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(c.X, c.X, c.X)
+	api.AssertIsEqual(api.Add(x3, c.X, 5), c.Y)
+	return nil
+}
+
+func compileToy(t *testing.T) *cs.R1CS {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &toyCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ccs.(*cs.R1CS)
+}
+
+// phase-1 ceremony of 2^3 with one contribution closed by the beacon
+func phase1Ceremony(t *testing.T) string {
+	dir := filepath.Join(t.TempDir(), "phase1")
+	if _, err := InitPhase1(dir, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ContributePhase1(dir, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyPhase1Beacon(dir, []byte("phase-1 beacon"), 1); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// proves and verifies the toy circuit with the keys
+func proveToy(t *testing.T, ccs *cs.R1CS, pk groth16.ProvingKey, vk groth16.VerifyingKey) {
+	t.Helper()
+	w, err := frontend.NewWitness(&toyCircuit{X: 3, Y: 35}, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := groth16.Prove(ccs, pk, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := w.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := groth16.Verify(proof, vk, public); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCeremony(t *testing.T) {
+	phase1Dir := phase1Ceremony(t)
+	if err := VerifyPhase1(phase1Dir); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "phase2")
+	ccs := compileToy(t)
+	if _, err := Init(dir, "TOY", ccs, phase1Dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Contribute(dir, "TOY", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Contribute(dir, "TOY", "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyBeacon(dir, "TOY", []byte("phase-2 beacon"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Contribute(dir, "TOY", "carol"); err == nil {
+		t.Fatal("contribution accepted after the beacon")
+	}
+
+	if err := Verify(dir, "TOY", phase1Dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// the phase-1 ceremony was verified by Init, verifying and finalizing do not need it anymore
+	if err := os.RemoveAll(phase1Dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(dir, "TOY", ""); err != nil {
+		t.Fatal(err)
+	}
+	compiled, pk, vk, err := Finalize(dir, "TOY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proveToy(t, compiled, pk, vk)
+
+	// the extracted keys are recorded and verified from now on
+	tr, err := LoadTranscript(dir, "TOY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Keys == nil {
+		t.Fatal("keys are not recorded in the transcript")
+	}
+	if err := Verify(dir, "TOY", ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCeremonyRejectsTamperedContribution(t *testing.T) {
+	phase1Dir := phase1Ceremony(t)
+	dir := filepath.Join(t.TempDir(), "phase2")
+	if _, err := Init(dir, "TOY", compileToy(t), phase1Dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Contribute(dir, "TOY", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Contribute(dir, "TOY", "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	// alice's contribution keeps its hash but its δ is replaced by bob's
+	tr, err := LoadTranscript(dir, "TOY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := tr.readContribution(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := tr.readContribution(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice.Parameters.G1.Delta = bob.Parameters.G1.Delta
+	alice.Parameters.G2.Delta = bob.Parameters.G2.Delta
+	if err := writeTo(filepath.Join(dir, tr.Contributions[1].File), alice); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Verify(dir, "TOY", "")
+	if err == nil || !strings.Contains(err.Error(), "contribution 1 by alice") {
+		t.Fatalf("expected alice's contribution to be rejected, got %v", err)
+	}
+	if _, err := ApplyBeacon(dir, "TOY", []byte("beacon"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := Finalize(dir, "TOY"); err == nil {
+		t.Fatal("finalized a ceremony with a tampered contribution")
+	}
+}
+
+func TestCeremonyRejectsTamperedSRS(t *testing.T) {
+	phase1Dir := phase1Ceremony(t)
+	dir := filepath.Join(t.TempDir(), "phase2")
+	if _, err := Init(dir, "TOY", compileToy(t), phase1Dir); err != nil {
+		t.Fatal(err)
+	}
+
+	srs, err := os.ReadFile(srsPath(dir, "TOY"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srs[len(srs)-1] ^= 1
+	if err := os.WriteFile(srsPath(dir, "TOY"), srs, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(dir, "TOY", ""); err == nil {
+		t.Fatal("verified with a modified phase-1 SRS")
+	}
+}
+
+func TestPhase1RejectsTamperedContribution(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "phase1")
+	if _, err := InitPhase1(dir, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ContributePhase1(dir, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ContributePhase1(dir, "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	// alice's contribution keeps its hash but one of its powers is replaced
+	tr, err := LoadPhase1Transcript(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := tr.readContribution(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice.Parameters.G1.Tau[2] = alice.Parameters.G1.Tau[3]
+	if err := writeTo(filepath.Join(dir, tr.Contributions[1].File), alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifyPhase1(dir); err == nil || !strings.Contains(err.Error(), "contribution 1 by alice") {
+		t.Fatalf("expected alice's contribution to be rejected, got %v", err)
+	}
+}
+
+func TestInitRequiresClosedPhase1(t *testing.T) {
+	phase1Dir := filepath.Join(t.TempDir(), "phase1")
+	if _, err := InitPhase1(phase1Dir, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ContributePhase1(phase1Dir, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Init(filepath.Join(t.TempDir(), "phase2"), "TOY", compileToy(t), phase1Dir); err == nil {
+		t.Fatal("started from a phase-1 ceremony without beacon")
+	}
+}
@@ -0,0 +1,288 @@
+package ceremony
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+)
+
+/*
+	Phase-1 ceremony (powers of τ) shared by the circuits, all state lives in one directory that is passed between participants
+
+	phase1.0000               initial SRS of 2^power, every element is a generator
+	phase1.0001 ...           one file per contribution, the beacon is the last one
+	phase1.transcript.json    hashes of every step, used to verify the ceremony
+
+The SRS of an external ceremony can be imported from a snarkjs .ptau file instead (see ptau.go), it takes no
+contributions. The phase-2 ceremonies only start from the last contribution of a phase-1 ceremony closed by the beacon,
+or from an imported SRS, and verify it once when they are initialized.
+*/
+
+// Phase1Transcript records every step of the phase-1 ceremony
+type Phase1Transcript struct {
+	// the SRS supports circuits of up to 2^Power constraints
+	Power         int            `json:"power"`
+	Import        *Phase1Import  `json:"import,omitempty"`
+	Contributions []Contribution `json:"contributions"`
+	Beacon        *Beacon        `json:"beacon,omitempty"`
+}
+
+// Final reports whether phase-2 ceremonies can start from the SRS
+func (t *Phase1Transcript) Final() bool {
+	return t.Beacon != nil || t.Import != nil
+}
+
+// largest power of the SRS, the two-adicity of the BN254 scalar field
+const maxPower = 28
+
+func phase1TranscriptPath(dir string) string { return filepath.Join(dir, "phase1.transcript.json") }
+func phase1File(index int) string            { return fmt.Sprintf("phase1.%04d", index) }
+
+// InitPhase1 starts the phase-1 ceremony of an SRS for circuits of up to 2^power constraints
+func InitPhase1(dir string, power int) (*Phase1Transcript, error) {
+	if power < 1 || power > maxPower {
+		return nil, fmt.Errorf("power must be between 1 and %d", maxPower)
+	}
+	if _, err := os.Stat(phase1TranscriptPath(dir)); err == nil {
+		return nil, fmt.Errorf("phase-1 ceremony already exists in %s", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	srs1 := mpcsetup.InitPhase1(power)
+	file := phase1File(0)
+	if err := writeTo(filepath.Join(dir, file), &srs1); err != nil {
+		return nil, err
+	}
+
+	t := &Phase1Transcript{
+		Power:         power,
+		Contributions: []Contribution{{Index: 0, Participant: "init", File: file, Hash: hex.EncodeToString(srs1.Hash), Time: time.Now().UTC()}},
+	}
+	return t, t.save(dir)
+}
+
+// ContributePhase1 adds fresh randomness on top of the last contribution, the toxic waste never leaves this function
+func ContributePhase1(dir, participant string) (*Contribution, error) {
+	t, err := LoadPhase1Transcript(dir)
+	if err != nil {
+		return nil, err
+	}
+	if t.Import != nil {
+		return nil, errors.New("the SRS is imported, it takes no contributions")
+	}
+	if t.Beacon != nil {
+		return nil, errors.New("the beacon has been applied, no more contributions are accepted")
+	}
+
+	prev, err := t.readContribution(dir, len(t.Contributions)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	next := clonePhase1(prev)
+	next.Contribute()
+
+	if err := mpcsetup.VerifyPhase1(prev, next); err != nil {
+		return nil, fmt.Errorf("verifying own contribution: %w", err)
+	}
+
+	return t.append(dir, participant, next)
+}
+
+// ApplyPhase1Beacon applies the public random beacon as the last contribution, anyone can recompute it
+func ApplyPhase1Beacon(dir string, beacon []byte, iterations int) (*Contribution, error) {
+	t, err := LoadPhase1Transcript(dir)
+	if err != nil {
+		return nil, err
+	}
+	if t.Import != nil {
+		return nil, errors.New("the SRS is imported, it takes no beacon")
+	}
+	if t.Beacon != nil {
+		return nil, errors.New("the beacon has already been applied")
+	}
+	if len(t.Contributions) < 2 {
+		return nil, errors.New("at least one contribution is required before the beacon")
+	}
+
+	prev, err := t.readContribution(dir, len(t.Contributions)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	next := clonePhase1(prev)
+	if err := contributePhase1Beacon(next, beacon, iterations); err != nil {
+		return nil, err
+	}
+
+	t.Beacon = &Beacon{Value: hex.EncodeToString(beacon), Iterations: iterations, Index: len(t.Contributions)}
+	return t.append(dir, "beacon", next)
+}
+
+// VerifyPhase1 checks the whole phase-1 ceremony: the initial SRS, every contribution and the beacon,
+// or the powers of an imported SRS
+func VerifyPhase1(dir string) error {
+	t, err := LoadPhase1Transcript(dir)
+	if err != nil {
+		return err
+	}
+	_, err = t.verify(dir)
+	return err
+}
+
+// verifies the chain of contributions from the generators, or the imported SRS, and returns the last one
+func (t *Phase1Transcript) verify(dir string) (*mpcsetup.Phase1, error) {
+	if t.Power < 1 || t.Power > maxPower {
+		return nil, fmt.Errorf("power must be between 1 and %d", maxPower)
+	}
+	if t.Import != nil {
+		return t.verifyImport(dir)
+	}
+
+	// the proofs of knowledge of the initial SRS are random, its parameters are the generators
+	initial, err := t.readContribution(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkInitialPhase1(initial, t.Power); err != nil {
+		return nil, err
+	}
+
+	prev := initial
+	for i := 1; i < len(t.Contributions); i++ {
+		next, err := t.readContribution(dir, i)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPhase1Size(next, t.Power); err != nil {
+			return nil, fmt.Errorf("contribution %d by %s: %w", i, t.Contributions[i].Participant, err)
+		}
+		if err := mpcsetup.VerifyPhase1(prev, next); err != nil {
+			return nil, fmt.Errorf("contribution %d by %s: %w", i, t.Contributions[i].Participant, err)
+		}
+
+		if t.Beacon != nil && t.Beacon.Index == i {
+			beacon, err := hex.DecodeString(t.Beacon.Value)
+			if err != nil {
+				return nil, fmt.Errorf("beacon value: %w", err)
+			}
+			recomputed := clonePhase1(prev)
+			if err := contributePhase1Beacon(recomputed, beacon, t.Beacon.Iterations); err != nil {
+				return nil, err
+			}
+			if !bytes.Equal(recomputed.Hash, next.Hash) {
+				return nil, errors.New("beacon contribution does not match the beacon value")
+			}
+		}
+		prev = next
+	}
+
+	if t.Beacon != nil && t.Beacon.Index != len(t.Contributions)-1 {
+		return nil, errors.New("the beacon must be the last contribution")
+	}
+	return prev, nil
+}
+
+func checkPhase1Size(p *mpcsetup.Phase1, power int) error {
+	n := 1 << power
+	if len(p.Parameters.G1.Tau) != 2*n-1 || len(p.Parameters.G1.AlphaTau) != n || len(p.Parameters.G1.BetaTau) != n || len(p.Parameters.G2.Tau) != n {
+		return fmt.Errorf("SRS size does not match 2^%d", power)
+	}
+	return nil
+}
+
+func checkInitialPhase1(p *mpcsetup.Phase1, power int) error {
+	if err := checkPhase1Size(p, power); err != nil {
+		return err
+	}
+	_, _, g1, g2 := curve.Generators()
+	for _, points := range [][]curve.G1Affine{p.Parameters.G1.Tau, p.Parameters.G1.AlphaTau, p.Parameters.G1.BetaTau} {
+		for i := range points {
+			if !points[i].Equal(&g1) {
+				return errors.New("initial SRS is not made of the generators")
+			}
+		}
+	}
+	for i := range p.Parameters.G2.Tau {
+		if !p.Parameters.G2.Tau[i].Equal(&g2) {
+			return errors.New("initial SRS is not made of the generators")
+		}
+	}
+	if !p.Parameters.G2.Beta.Equal(&g2) {
+		return errors.New("initial SRS is not made of the generators")
+	}
+	if !bytes.Equal(hashPhase1(p), p.Hash) {
+		return errors.New("initial SRS hash does not match its parameters")
+	}
+	return nil
+}
+
+// reads the contribution at index and checks its hash against the transcript
+func (t *Phase1Transcript) readContribution(dir string, index int) (*mpcsetup.Phase1, error) {
+	c := t.Contributions[index]
+
+	var p mpcsetup.Phase1
+	if err := readFrom(filepath.Join(dir, c.File), &p); err != nil {
+		return nil, err
+	}
+	if len(p.Hash) != sha256.Size || hex.EncodeToString(p.Hash) != c.Hash {
+		return nil, fmt.Errorf("phase-1 contribution %d does not match the transcript", index)
+	}
+	return &p, nil
+}
+
+func (t *Phase1Transcript) append(dir, participant string, p *mpcsetup.Phase1) (*Contribution, error) {
+	index := len(t.Contributions)
+	file := phase1File(index)
+	if err := writeTo(filepath.Join(dir, file), p); err != nil {
+		return nil, err
+	}
+
+	c := Contribution{Index: index, Participant: participant, File: file, Hash: hex.EncodeToString(p.Hash), Time: time.Now().UTC()}
+	t.Contributions = append(t.Contributions, c)
+	return &c, t.save(dir)
+}
+
+func (t *Phase1Transcript) save(dir string) error {
+	out, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(phase1TranscriptPath(dir), out, 0644)
+}
+
+func LoadPhase1Transcript(dir string) (*Phase1Transcript, error) {
+	in, err := os.ReadFile(phase1TranscriptPath(dir))
+	if err != nil {
+		return nil, err
+	}
+	var t Phase1Transcript
+	if err := json.Unmarshal(in, &t); err != nil {
+		return nil, fmt.Errorf("parsing phase-1 transcript: %w", err)
+	}
+	if len(t.Contributions) == 0 {
+		return nil, errors.New("phase-1 transcript has no initial contribution")
+	}
+	return &t, nil
+}
+
+func clonePhase1(p *mpcsetup.Phase1) *mpcsetup.Phase1 {
+	c := *p
+	c.Parameters.G1.Tau = append(c.Parameters.G1.Tau[:0:0], p.Parameters.G1.Tau...)
+	c.Parameters.G1.AlphaTau = append(c.Parameters.G1.AlphaTau[:0:0], p.Parameters.G1.AlphaTau...)
+	c.Parameters.G1.BetaTau = append(c.Parameters.G1.BetaTau[:0:0], p.Parameters.G1.BetaTau...)
+	c.Parameters.G2.Tau = append(c.Parameters.G2.Tau[:0:0], p.Parameters.G2.Tau...)
+	c.Hash = append(c.Hash[:0:0], p.Hash...)
+	return &c
+}
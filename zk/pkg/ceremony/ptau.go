@@ -0,0 +1,377 @@
+package ceremony
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+)
+
+/*
+	Import of the SRS of an external powers of τ ceremony from a snarkjs .ptau file, e.g. the Perpetual Powers of Tau
+
+	"ptau" | version u32 | nSections u32, then every section as type u32 | size u64 | data
+	section 1   n8 u32 | q (n8 bytes) | power u32 | ceremonyPower u32
+	section 2   [τ⁰]₁ … [τ²ⁿ⁻²]₁
+	section 3   [τ⁰]₂ … [τⁿ⁻¹]₂
+	section 4   α[τ⁰]₁ … α[τⁿ⁻¹]₁
+	section 5   β[τ⁰]₁ … β[τⁿ⁻¹]₁
+	section 6   [β]₂
+
+Coordinates are little endian in Montgomery form, the in-memory layout of fp.Element. The contributions of the
+external ceremony (section 7) are not verified here, check them with snarkjs powersoftau verify, the SRS itself
+is checked to be made of consistent powers and the sha256 of the file is pinned in the transcript.
+*/
+
+// Phase1Import records the .ptau file an imported SRS was read from
+type Phase1Import struct {
+	File   string `json:"file"`
+	Sha256 string `json:"sha256"`
+	// power of the file, the imported SRS may be truncated to a lower one
+	Power int `json:"power"`
+}
+
+const (
+	ptauHeader = 1 + iota
+	ptauTauG1
+	ptauTauG2
+	ptauAlphaTauG1
+	ptauBetaTauG1
+	ptauBetaG2
+)
+
+// ImportPhase1 imports the SRS of the .ptau file as a final phase-1 ceremony for circuits of up to 2^power constraints,
+// 0 keeps every power of the file
+func ImportPhase1(dir, ptauPath string, power int) (*Phase1Transcript, error) {
+	if _, err := os.Stat(phase1TranscriptPath(dir)); err == nil {
+		return nil, fmt.Errorf("phase-1 ceremony already exists in %s", dir)
+	}
+
+	sum, err := sha256FileHex(ptauPath)
+	if err != nil {
+		return nil, err
+	}
+	srs1, power, filePower, err := readPtau(ptauPath, power)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ptauPath, err)
+	}
+	if err := checkPowers(srs1); err != nil {
+		return nil, fmt.Errorf("%s: %w", ptauPath, err)
+	}
+	srs1.Hash = hashPhase1(srs1)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	file := phase1File(0)
+	if err := writeTo(filepath.Join(dir, file), srs1); err != nil {
+		return nil, err
+	}
+
+	t := &Phase1Transcript{
+		Power:         power,
+		Import:        &Phase1Import{File: filepath.Base(ptauPath), Sha256: sum, Power: filePower},
+		Contributions: []Contribution{{Index: 0, Participant: "import", File: file, Hash: hex.EncodeToString(srs1.Hash), Time: time.Now().UTC()}},
+	}
+	return t, t.save(dir)
+}
+
+// verifies the imported SRS against the transcript, it is the final one
+func (t *Phase1Transcript) verifyImport(dir string) (*mpcsetup.Phase1, error) {
+	if len(t.Contributions) != 1 || t.Beacon != nil {
+		return nil, errors.New("an imported SRS takes no contributions")
+	}
+	srs1, err := t.readContribution(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPhase1Size(srs1, t.Power); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hashPhase1(srs1), srs1.Hash) {
+		return nil, errors.New("imported SRS hash does not match its parameters")
+	}
+	if err := checkPowers(srs1); err != nil {
+		return nil, err
+	}
+	return srs1, nil
+}
+
+// reads the powers of the .ptau file truncated to 2^power, returns the power read and the power of the file
+func readPtau(path string, power int) (*mpcsetup.Phase1, int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer f.Close()
+
+	var magic [4]byte
+	if _, err := io.ReadFull(f, magic[:]); err != nil {
+		return nil, 0, 0, err
+	}
+	if string(magic[:]) != "ptau" {
+		return nil, 0, 0, errors.New("not a ptau file")
+	}
+	var header struct{ Version, NbSections uint32 }
+	if err := binary.Read(f, binary.LittleEndian, &header); err != nil {
+		return nil, 0, 0, err
+	}
+
+	// offset and size of every section
+	sections := make(map[uint32][2]int64)
+	offset := int64(12)
+	for i := uint32(0); i < header.NbSections; i++ {
+		var section struct {
+			Type uint32
+			Size uint64
+		}
+		if err := binary.Read(f, binary.LittleEndian, &section); err != nil {
+			return nil, 0, 0, err
+		}
+		offset += 12
+		sections[section.Type] = [2]int64{offset, int64(section.Size)}
+		offset += int64(section.Size)
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	section := func(typ uint32, size int64) (*bufio.Reader, error) {
+		s, ok := sections[typ]
+		if !ok {
+			return nil, fmt.Errorf("missing section %d", typ)
+		}
+		if s[1] < size {
+			return nil, fmt.Errorf("section %d holds %d bytes, expected at least %d", typ, s[1], size)
+		}
+		return bufio.NewReader(io.NewSectionReader(f, s[0], size)), nil
+	}
+
+	r, err := section(ptauHeader, 4+fp.Bytes+8)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	var n8 uint32
+	if err := binary.Read(r, binary.LittleEndian, &n8); err != nil {
+		return nil, 0, 0, err
+	}
+	q := make([]byte, fp.Bytes)
+	if _, err := io.ReadFull(r, q); err != nil {
+		return nil, 0, 0, err
+	}
+	if n8 != fp.Bytes || new(big.Int).SetBytes(reverse(q)).Cmp(fp.Modulus()) != 0 {
+		return nil, 0, 0, errors.New("the SRS is not defined over bn254")
+	}
+	var filePower uint32
+	if err := binary.Read(r, binary.LittleEndian, &filePower); err != nil {
+		return nil, 0, 0, err
+	}
+	if filePower < 1 || filePower > maxPower {
+		return nil, 0, 0, fmt.Errorf("power %d must be between 1 and %d", filePower, maxPower)
+	}
+	if power == 0 {
+		power = int(filePower)
+	}
+	if power < 1 || power > int(filePower) {
+		return nil, 0, 0, fmt.Errorf("power must be between 1 and %d, the power of the file", filePower)
+	}
+
+	n := 1 << power
+	var srs1 mpcsetup.Phase1
+	srs1.Parameters.G1.Tau = make([]curve.G1Affine, 2*n-1)
+	srs1.Parameters.G1.AlphaTau = make([]curve.G1Affine, n)
+	srs1.Parameters.G1.BetaTau = make([]curve.G1Affine, n)
+	srs1.Parameters.G2.Tau = make([]curve.G2Affine, n)
+	beta := make([]curve.G2Affine, 1)
+
+	for _, s := range []struct {
+		typ uint32
+		g1  []curve.G1Affine
+		g2  []curve.G2Affine
+	}{
+		{typ: ptauTauG1, g1: srs1.Parameters.G1.Tau},
+		{typ: ptauTauG2, g2: srs1.Parameters.G2.Tau},
+		{typ: ptauAlphaTauG1, g1: srs1.Parameters.G1.AlphaTau},
+		{typ: ptauBetaTauG1, g1: srs1.Parameters.G1.BetaTau},
+		{typ: ptauBetaG2, g2: beta},
+	} {
+		r, err := section(s.typ, int64(len(s.g1))*2*fp.Bytes+int64(len(s.g2))*4*fp.Bytes)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if err := readG1(r, s.g1); err != nil {
+			return nil, 0, 0, fmt.Errorf("section %d: %w", s.typ, err)
+		}
+		if err := readG2(r, s.g2); err != nil {
+			return nil, 0, 0, fmt.Errorf("section %d: %w", s.typ, err)
+		}
+	}
+	srs1.Parameters.G2.Beta = beta[0]
+
+	return &srs1, power, int(filePower), nil
+}
+
+func readG1(r io.Reader, points []curve.G1Affine) error {
+	buf := make([]byte, 2*fp.Bytes)
+	for i := range points {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		if err := setMontgomery(&points[i].X, buf[:fp.Bytes]); err != nil {
+			return err
+		}
+		if err := setMontgomery(&points[i].Y, buf[fp.Bytes:]); err != nil {
+			return err
+		}
+		if points[i].IsInfinity() || !points[i].IsInSubGroup() {
+			return fmt.Errorf("point %d is not in G1", i)
+		}
+	}
+	return nil
+}
+
+func readG2(r io.Reader, points []curve.G2Affine) error {
+	buf := make([]byte, 4*fp.Bytes)
+	for i := range points {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		for j, e := range []*fp.Element{&points[i].X.A0, &points[i].X.A1, &points[i].Y.A0, &points[i].Y.A1} {
+			if err := setMontgomery(e, buf[j*fp.Bytes:(j+1)*fp.Bytes]); err != nil {
+				return err
+			}
+		}
+		if points[i].IsInfinity() || !points[i].IsInSubGroup() {
+			return fmt.Errorf("point %d is not in G2", i)
+		}
+	}
+	return nil
+}
+
+// sets the element from its little endian Montgomery form, which must be reduced
+func setMontgomery(e *fp.Element, b []byte) error {
+	if new(big.Int).SetBytes(reverse(b)).Cmp(fp.Modulus()) >= 0 {
+		return errors.New("coordinate is not reduced")
+	}
+	for i := range e {
+		e[i] = binary.LittleEndian.Uint64(b[8*i:])
+	}
+	return nil
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+// checks the SRS is made of the powers of a single τ, α and β, starting from the generators. Each sequence
+// is checked at once on a random linear combination: Σrᵢ[τⁱ⁺¹] must pair like Σrᵢ[τⁱ] times τ.
+func checkPowers(srs1 *mpcsetup.Phase1) error {
+	p := &srs1.Parameters
+	_, _, g1, g2 := curve.Generators()
+	if !p.G1.Tau[0].Equal(&g1) || !p.G2.Tau[0].Equal(&g2) {
+		return errors.New("the SRS does not start from the generators")
+	}
+	if p.G1.Tau[1].Equal(&g1) {
+		return errors.New("the SRS has τ = 1")
+	}
+	tau1, tau2 := p.G1.Tau[1], p.G2.Tau[1]
+
+	for _, c := range []struct {
+		name   string
+		points []curve.G1Affine
+	}{{"[τⁱ]₁", p.G1.Tau}, {"α[τⁱ]₁", p.G1.AlphaTau}, {"β[τⁱ]₁", p.G1.BetaTau}} {
+		lower, upper, err := combineG1(c.points)
+		if err != nil {
+			return err
+		}
+		// e(Σrᵢ[τⁱ⁺¹]₁, [1]₂) = e(Σrᵢ[τⁱ]₁, [τ]₂)
+		lower.Neg(&lower)
+		if ok, err := curve.PairingCheck([]curve.G1Affine{upper, lower}, []curve.G2Affine{g2, tau2}); err != nil || !ok {
+			return fmt.Errorf("%s are not consecutive powers of τ", c.name)
+		}
+	}
+
+	lower, upper, err := combineG2(p.G2.Tau)
+	if err != nil {
+		return err
+	}
+	// e([1]₁, Σrᵢ[τⁱ⁺¹]₂) = e([τ]₁, Σrᵢ[τⁱ]₂)
+	tau1.Neg(&tau1)
+	if ok, err := curve.PairingCheck([]curve.G1Affine{g1, tau1}, []curve.G2Affine{upper, lower}); err != nil || !ok {
+		return errors.New("[τⁱ]₂ are not consecutive powers of τ")
+	}
+
+	// e(β[τ⁰]₁, [1]₂) = e([1]₁, [β]₂)
+	var beta curve.G1Affine
+	beta.Neg(&p.G1.BetaTau[0])
+	if ok, err := curve.PairingCheck([]curve.G1Affine{beta, g1}, []curve.G2Affine{g2, p.G2.Beta}); err != nil || !ok {
+		return errors.New("[β]₂ does not match β[τ⁰]₁")
+	}
+	return nil
+}
+
+// returns Σrᵢpᵢ and Σrᵢpᵢ₊₁ for random rᵢ
+func combineG1(points []curve.G1Affine) (lower, upper curve.G1Affine, err error) {
+	r, err := randomScalars(len(points) - 1)
+	if err != nil {
+		return lower, upper, err
+	}
+	if _, err = lower.MultiExp(points[:len(points)-1], r, ecc.MultiExpConfig{}); err != nil {
+		return lower, upper, err
+	}
+	_, err = upper.MultiExp(points[1:], r, ecc.MultiExpConfig{})
+	return lower, upper, err
+}
+
+func combineG2(points []curve.G2Affine) (lower, upper curve.G2Affine, err error) {
+	r, err := randomScalars(len(points) - 1)
+	if err != nil {
+		return lower, upper, err
+	}
+	if _, err = lower.MultiExp(points[:len(points)-1], r, ecc.MultiExpConfig{}); err != nil {
+		return lower, upper, err
+	}
+	_, err = upper.MultiExp(points[1:], r, ecc.MultiExpConfig{})
+	return lower, upper, err
+}
+
+func randomScalars(n int) ([]fr.Element, error) {
+	r := make([]fr.Element, n)
+	for i := range r {
+		if _, err := r[i].SetRandom(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func sha256FileHex(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
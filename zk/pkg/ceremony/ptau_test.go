@@ -0,0 +1,183 @@
+package ceremony
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+)
+
+// writes the powers of the SRS in the snarkjs .ptau layout, with an empty contributions section
+func writePtau(t *testing.T, path string, srs1 *mpcsetup.Phase1, power int) {
+	t.Helper()
+	var buf bytes.Buffer
+	le := func(v any) {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	element := func(e *fp.Element) {
+		for i := range e {
+			le(e[i])
+		}
+	}
+	g1 := func(points []curve.G1Affine) {
+		for i := range points {
+			element(&points[i].X)
+			element(&points[i].Y)
+		}
+	}
+	g2 := func(points []curve.G2Affine) {
+		for i := range points {
+			element(&points[i].X.A0)
+			element(&points[i].X.A1)
+			element(&points[i].Y.A0)
+			element(&points[i].Y.A1)
+		}
+	}
+	section := func(typ uint32, write func()) {
+		le(typ)
+		start := buf.Len()
+		le(uint64(0))
+		write()
+		binary.LittleEndian.PutUint64(buf.Bytes()[start:], uint64(buf.Len()-start-8))
+	}
+
+	buf.WriteString("ptau")
+	le(uint32(1))
+	le(uint32(7))
+	section(ptauHeader, func() {
+		le(uint32(fp.Bytes))
+		q := fp.Modulus().FillBytes(make([]byte, fp.Bytes))
+		buf.Write(reverse(q))
+		le(uint32(power))
+		le(uint32(power))
+	})
+	// sections do not have to be in order
+	section(7, func() { le(uint32(0)) })
+	section(ptauTauG1, func() { g1(srs1.Parameters.G1.Tau) })
+	section(ptauTauG2, func() { g2(srs1.Parameters.G2.Tau) })
+	section(ptauAlphaTauG1, func() { g1(srs1.Parameters.G1.AlphaTau) })
+	section(ptauBetaTauG1, func() { g1(srs1.Parameters.G1.BetaTau) })
+	section(ptauBetaG2, func() { g2([]curve.G2Affine{srs1.Parameters.G2.Beta}) })
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// SRS of 2^4 with a secret τ, α and β
+func ptauSRS(t *testing.T) *mpcsetup.Phase1 {
+	srs1 := mpcsetup.InitPhase1(4)
+	srs1.Contribute()
+	return &srs1
+}
+
+func TestImportPhase1(t *testing.T) {
+	srs1 := ptauSRS(t)
+	ptau := filepath.Join(t.TempDir(), "pot4.ptau")
+	writePtau(t, ptau, srs1, 4)
+
+	phase1Dir := filepath.Join(t.TempDir(), "phase1")
+	tr, err := ImportPhase1(phase1Dir, ptau, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := sha256FileHex(ptau)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Import == nil || tr.Import.Sha256 != sum || tr.Import.Power != 4 || tr.Power != 3 {
+		t.Fatalf("unexpected transcript %+v", tr)
+	}
+
+	// the imported SRS is the truncated SRS of the file
+	imported, err := tr.readContribution(phase1Dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported.Parameters.G1.Tau) != 15 || !imported.Parameters.G1.Tau[14].Equal(&srs1.Parameters.G1.Tau[14]) ||
+		!imported.Parameters.G2.Tau[7].Equal(&srs1.Parameters.G2.Tau[7]) || !imported.Parameters.G2.Beta.Equal(&srs1.Parameters.G2.Beta) {
+		t.Fatal("imported SRS differs from the file")
+	}
+
+	if err := VerifyPhase1(phase1Dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ContributePhase1(phase1Dir, "alice"); err == nil {
+		t.Fatal("contribution accepted on an imported SRS")
+	}
+
+	// a phase-2 ceremony starts from the imported SRS and pins the hash of the file
+	dir := filepath.Join(t.TempDir(), "phase2")
+	ccs := compileToy(t)
+	t2, err := Init(dir, "TOY", ccs, phase1Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if t2.Phase1.Ptau != sum {
+		t.Fatalf("phase-2 transcript pins %s, expected %s", t2.Phase1.Ptau, sum)
+	}
+	if _, err := Contribute(dir, "TOY", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyBeacon(dir, "TOY", []byte("beacon"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(dir, "TOY", phase1Dir); err != nil {
+		t.Fatal(err)
+	}
+	compiled, pk, vk, err := Finalize(dir, "TOY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proveToy(t, compiled, pk, vk)
+}
+
+func TestImportPhase1Rejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(srs1 *mpcsetup.Phase1)
+	}{
+		{name: "tau", tamper: func(srs1 *mpcsetup.Phase1) { srs1.Parameters.G1.Tau[5] = srs1.Parameters.G1.Tau[6] }},
+		{name: "alpha", tamper: func(srs1 *mpcsetup.Phase1) { srs1.Parameters.G1.AlphaTau[2] = srs1.Parameters.G1.Tau[2] }},
+		{name: "beta", tamper: func(srs1 *mpcsetup.Phase1) { srs1.Parameters.G2.Beta = srs1.Parameters.G2.Tau[1] }},
+		{name: "g2", tamper: func(srs1 *mpcsetup.Phase1) { srs1.Parameters.G2.Tau[3] = srs1.Parameters.G2.Tau[2] }},
+		{name: "generators", tamper: func(srs1 *mpcsetup.Phase1) { srs1.Parameters.G1.Tau[0] = srs1.Parameters.G1.Tau[1] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srs1 := ptauSRS(t)
+			tt.tamper(srs1)
+			ptau := filepath.Join(t.TempDir(), "pot4.ptau")
+			writePtau(t, ptau, srs1, 4)
+
+			if _, err := ImportPhase1(filepath.Join(t.TempDir(), "phase1"), ptau, 0); err == nil {
+				t.Fatal("imported an inconsistent SRS")
+			}
+		})
+	}
+
+	t.Run("power", func(t *testing.T) {
+		ptau := filepath.Join(t.TempDir(), "pot4.ptau")
+		writePtau(t, ptau, ptauSRS(t), 4)
+		if _, err := ImportPhase1(filepath.Join(t.TempDir(), "phase1"), ptau, 5); err == nil {
+			t.Fatal("imported more powers than the file holds")
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		ptau := filepath.Join(t.TempDir(), "pot4.ptau")
+		if err := os.WriteFile(ptau, []byte("zkey\x01\x00\x00\x00\x00\x00\x00\x00"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ImportPhase1(filepath.Join(t.TempDir(), "phase1"), ptau, 0); err == nil {
+			t.Fatal("imported a file that is not a ptau")
+		}
+	})
+}
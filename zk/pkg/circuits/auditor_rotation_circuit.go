@@ -0,0 +1,35 @@
+package circuits
+
+import (
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/consensys/gnark/frontend"
+)
+
+// AuditorRotationCircuit proves that an auditor PCT emitted for the old auditor and its re-encryption
+// for the new auditor hold the same value, without revealing the value
+type AuditorRotationCircuit struct {
+	OldAuditor OutgoingAuditor
+	NewAuditor Auditor
+	Value      frontend.Variable
+}
+
+func (circuit *AuditorRotationCircuit) Define(api frontend.API) error {
+	return DefineChecks(api, circuit.Checks())
+}
+
+func (circuit *AuditorRotationCircuit) Checks() []Check {
+	return []Check{
+		// Verify the old auditor's private key generates the old auditor's public key
+		{Name: "CheckPublicKey", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPublicKey(api, bj, circuit.OldAuditor)
+		}},
+		// Verify the historical PCT decrypts to the value with the old auditor's private key
+		{Name: "CheckPCTDecryption", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPCTDecryption(api, bj, circuit.OldAuditor, circuit.OldAuditor.PCT, circuit.Value)
+		}},
+		// Verify the new PCT encrypts the same value with the new auditor's public key
+		{Name: "CheckPCTAuditor", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPCTAuditor(api, bj, circuit.NewAuditor, circuit.Value)
+		}},
+	}
+}
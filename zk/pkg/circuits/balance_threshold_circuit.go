@@ -0,0 +1,58 @@
+package circuits
+
+import (
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/consensys/gnark/frontend"
+)
+
+// BalanceThresholdCircuit proves the balance encrypted in the holder's balance EGCT is in [Threshold, UpperBound]
+// without revealing it. The upper bound is BasePointOrder - 1 when the proof only claims a minimum, the challenge
+// is chosen by the verifier so the proof can not be replayed to another one. The upper bound is asserted to be at most
+// BasePointOrder - 1 in the circuit as well, so the statement does not rely on the builder or on CheckBalance capping
+// the balance to be meaningful.
+type BalanceThresholdCircuit struct {
+	Sender     WithdrawSender
+	Threshold  frontend.Variable `gnark:",public"`
+	UpperBound frontend.Variable `gnark:",public"`
+	Challenge  frontend.Variable `gnark:",public"`
+}
+
+func (circuit *BalanceThresholdCircuit) Define(api frontend.API) error {
+	return DefineChecks(api, circuit.Checks())
+}
+
+func (circuit *BalanceThresholdCircuit) Checks() []Check {
+	return []Check{
+		// Verify the balance is greater than or equal to the threshold
+		{Name: "CheckThreshold", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			api.AssertIsLessOrEqual(circuit.Threshold, circuit.Sender.Balance)
+		}},
+		// Verify the balance is less than or equal to the upper bound, itself at most BasePointOrder - 1
+		{Name: "CheckUpperBound", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			api.AssertIsLessOrEqual(circuit.UpperBound, api.Sub(bj.BasePointOrder, 1))
+			api.AssertIsLessOrEqual(circuit.Sender.Balance, circuit.UpperBound)
+		}},
+		// Verify sender's public key is well-formed
+		{Name: "CheckPublicKey", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPublicKey(api, bj, Sender{
+				PrivateKey: circuit.Sender.PrivateKey,
+				PublicKey:  circuit.Sender.PublicKey,
+			})
+		}},
+		// Verify sender's encrypted balance is well-formed
+		{Name: "CheckBalance", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckBalance(api, bj, Sender{
+				PrivateKey:  circuit.Sender.PrivateKey,
+				PublicKey:   circuit.Sender.PublicKey,
+				Balance:     circuit.Sender.Balance,
+				BalanceEGCT: circuit.Sender.BalanceEGCT,
+			})
+		}},
+		// Bind the challenge to the proof. A public input that appears in no constraint is not bound by the
+		// proof and could be swapped for any other challenge, the dummy square (as in circom) puts it in one
+		// multiplication constraint whose result is deliberately left unused.
+		{Name: "CheckChallenge", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			api.Mul(circuit.Challenge, circuit.Challenge)
+		}},
+	}
+}
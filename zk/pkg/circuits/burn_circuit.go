@@ -0,0 +1,41 @@
+package circuits
+
+import (
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/consensys/gnark/frontend"
+)
+
+type BurnCircuit struct {
+	Sender      Sender
+	Auditor     Auditor
+	ValueToBurn frontend.Variable
+}
+
+func (circuit *BurnCircuit) Define(api frontend.API) error {
+	return DefineChecks(api, circuit.Checks())
+}
+
+func (circuit *BurnCircuit) Checks() []Check {
+	return []Check{
+		// Verify the burn amount is less than or equal to the sender's balance
+		{Name: "CheckSufficientBalance", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			api.AssertIsLessOrEqual(circuit.ValueToBurn, circuit.Sender.Balance)
+		}},
+		// Verify sender's public key is well-formed
+		{Name: "CheckPublicKey", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPublicKey(api, bj, circuit.Sender)
+		}},
+		// Verify sender's encrypted balance is well-formed
+		{Name: "CheckBalance", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckBalance(api, bj, circuit.Sender)
+		}},
+		// Verify sender's encrypted value is the burn amount
+		{Name: "CheckPositiveValue", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPositiveValue(api, bj, circuit.Sender, circuit.ValueToBurn)
+		}},
+		// Verify auditor's encrypted summary includes the burn amount and is encrypted with the auditor's public key
+		{Name: "CheckPCTAuditor", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPCTAuditor(api, bj, circuit.Auditor, circuit.ValueToBurn)
+		}},
+	}
+}
@@ -0,0 +1,31 @@
+package circuits
+
+import (
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Check is a named group of constraints, the circuits are defined as a sequence of checks
+// so an unsatisfied witness can be traced back to the check it fails
+type Check struct {
+	Name string
+	Run  func(api frontend.API, bj *babyjub.BjWrapper)
+}
+
+// CheckedCircuit is a circuit whose constraints are defined by its checks
+type CheckedCircuit interface {
+	frontend.Circuit
+	Checks() []Check
+}
+
+// DefineChecks defines the constraints of every check in order
+func DefineChecks(api frontend.API, checks []Check) error {
+	// Initialize babyjub wrapper
+	bj := babyjub.NewBjWrapper(api, tedwards.BN254)
+
+	for _, check := range checks {
+		check.Run(api, bj)
+	}
+	return nil
+}
@@ -103,6 +103,20 @@ func CheckPCTAuditor(api frontend.API, bj *babyjub.BjWrapper, auditor Auditor, v
 	api.AssertIsEqual(decrypted[0], value)
 }
 
+/*
+CheckPCTDecryption verifies if the given emitted Poseidon ciphertext decrypts to the given value with the key holder's private key
+*/
+func CheckPCTDecryption(api frontend.API, bj *babyjub.BjWrapper, keyHolder KeyHolder, pct EmittedPCT, value frontend.Variable) {
+	bj.Curve.AssertIsOnCurve(pct.AuthKey)
+
+	// sk * authKey = r * pk
+	poseidonEncryptionKey := bj.MulWithScalar(pct.AuthKey.X, pct.AuthKey.Y, keyHolder.GetPrivateKey())
+
+	// Decrypt the ciphertext, the last element authenticates it
+	decrypted := poseidon.PoseidonDecryptSingle(api, [2]frontend.Variable{poseidonEncryptionKey.X, poseidonEncryptionKey.Y}, pct.Nonce, pct.Ciphertext)
+	api.AssertIsEqual(decrypted[0], value)
+}
+
 /*
 CheckRegistrationHash verifies if the given registration hash is well-formed
 */
@@ -144,3 +158,27 @@ func (s RegistrationSender) GetPublicKeyX() frontend.Variable {
 func (s RegistrationSender) GetPublicKeyY() frontend.Variable {
 	return s.PublicKey.P.Y
 }
+
+func (a OutgoingAuditor) GetPrivateKey() frontend.Variable {
+	return a.PrivateKey
+}
+
+func (a OutgoingAuditor) GetPublicKeyX() frontend.Variable {
+	return a.PublicKey.P.X
+}
+
+func (a OutgoingAuditor) GetPublicKeyY() frontend.Variable {
+	return a.PublicKey.P.Y
+}
+
+func (h Holder) GetPrivateKey() frontend.Variable {
+	return h.PrivateKey
+}
+
+func (h Holder) GetPublicKeyX() frontend.Variable {
+	return h.PublicKey.P.X
+}
+
+func (h Holder) GetPublicKeyY() frontend.Variable {
+	return h.PublicKey.P.Y
+}
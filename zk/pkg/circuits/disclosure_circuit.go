@@ -0,0 +1,60 @@
+package circuits
+
+import (
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/consensys/gnark/frontend"
+)
+
+// EGCTDisclosureCircuit proves the ElGamal ciphertext, e.g. the ValueEGCT of a transfer, decrypts to the disclosed
+// amount with the private key of the holder's public key, without revealing the key
+type EGCTDisclosureCircuit struct {
+	Holder    Holder
+	ValueEGCT ElGamalCiphertext
+	Amount    frontend.Variable `gnark:",public"`
+}
+
+func (circuit *EGCTDisclosureCircuit) Define(api frontend.API) error {
+	return DefineChecks(api, circuit.Checks())
+}
+
+func (circuit *EGCTDisclosureCircuit) Checks() []Check {
+	return []Check{
+		// Verify holder's public key is well-formed
+		{Name: "CheckPublicKey", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPublicKey(api, bj, circuit.Holder)
+		}},
+		// Verify the ciphertext decrypts to the disclosed amount
+		{Name: "CheckEGCTDecryption", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPositiveValue(api, bj, Sender{
+				PrivateKey: circuit.Holder.PrivateKey,
+				PublicKey:  circuit.Holder.PublicKey,
+				ValueEGCT:  circuit.ValueEGCT,
+			}, circuit.Amount)
+		}},
+	}
+}
+
+// PCTDisclosureCircuit proves the Poseidon ciphertext, e.g. the receiver PCT of a transfer, decrypts to the disclosed
+// amount with the private key of the holder's public key, without revealing the key
+type PCTDisclosureCircuit struct {
+	Holder Holder
+	PCT    EmittedPCT
+	Amount frontend.Variable `gnark:",public"`
+}
+
+func (circuit *PCTDisclosureCircuit) Define(api frontend.API) error {
+	return DefineChecks(api, circuit.Checks())
+}
+
+func (circuit *PCTDisclosureCircuit) Checks() []Check {
+	return []Check{
+		// Verify holder's public key is well-formed
+		{Name: "CheckPublicKey", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPublicKey(api, bj, circuit.Holder)
+		}},
+		// Verify the ciphertext decrypts to the disclosed amount
+		{Name: "CheckPCTDecryption", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPCTDecryption(api, bj, circuit.Holder, circuit.PCT, circuit.Amount)
+		}},
+	}
+}
@@ -2,7 +2,6 @@ package circuits
 
 import (
 	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
-	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark/frontend"
 )
 
@@ -13,21 +12,32 @@ type MintCircuit struct {
 	ValueToMint   frontend.Variable
 }
 
-func (circuit *MintCircuit) Define(api frontend.API) error {
-	// Initialize babyjub wrapper
-	babyjub := babyjub.NewBjWrapper(api, tedwards.BN254)
-
-	// Verify receiver's encrypted value is the mint amount
-	CheckValue(api, babyjub, circuit.Receiver, circuit.ValueToMint)
-
-	// Verify nullifier hash is not used
-	CheckNullifierHash(api, circuit.Auditor, circuit.MintNullifier)
-
-	// Verify receiver's encrypted summary includes the mint amount and is encrypted with the receiver's public key
-	CheckPCTReceiver(api, babyjub, circuit.Receiver, circuit.ValueToMint)
+// the verifier contract reads the chain id and the nullifier hash first, see utils.ContractOrdered
+func (circuit *MintCircuit) ContractOrder() []string {
+	return []string{"MintNullifier", "Receiver", "Auditor"}
+}
 
-	// Verify auditor's encrypted summary includes the mint amount and is encrypted with the auditor's public key
-	CheckPCTAuditor(api, babyjub, circuit.Auditor, circuit.ValueToMint)
+func (circuit *MintCircuit) Define(api frontend.API) error {
+	return DefineChecks(api, circuit.Checks())
+}
 
-	return nil
+func (circuit *MintCircuit) Checks() []Check {
+	return []Check{
+		// Verify receiver's encrypted value is the mint amount
+		{Name: "CheckValue", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckValue(api, bj, circuit.Receiver, circuit.ValueToMint)
+		}},
+		// Verify nullifier hash is not used
+		{Name: "CheckNullifierHash", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckNullifierHash(api, circuit.Auditor, circuit.MintNullifier)
+		}},
+		// Verify receiver's encrypted summary includes the mint amount and is encrypted with the receiver's public key
+		{Name: "CheckPCTReceiver", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPCTReceiver(api, bj, circuit.Receiver, circuit.ValueToMint)
+		}},
+		// Verify auditor's encrypted summary includes the mint amount and is encrypted with the auditor's public key
+		{Name: "CheckPCTAuditor", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPCTAuditor(api, bj, circuit.Auditor, circuit.ValueToMint)
+		}},
+	}
 }
@@ -2,7 +2,6 @@ package circuits
 
 import (
 	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
-	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark/frontend"
 )
 
@@ -11,14 +10,18 @@ type RegistrationCircuit struct {
 }
 
 func (circuit *RegistrationCircuit) Define(api frontend.API) error {
-	// Initialize babyjub wrapper
-	babyjub := babyjub.NewBjWrapper(api, tedwards.BN254)
-
-	// Verify that the sender's public key is well-formed
-	CheckPublicKey(api, babyjub, circuit.Sender)
-
-	// Verify that the sender's registration hash is well-formed
-	CheckRegistrationHash(api, circuit.Sender)
+	return DefineChecks(api, circuit.Checks())
+}
 
-	return nil
+func (circuit *RegistrationCircuit) Checks() []Check {
+	return []Check{
+		// Verify that the sender's public key is well-formed
+		{Name: "CheckPublicKey", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPublicKey(api, bj, circuit.Sender)
+		}},
+		// Verify that the sender's registration hash is well-formed
+		{Name: "CheckRegistrationHash", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckRegistrationHash(api, circuit.Sender)
+		}},
+	}
 }
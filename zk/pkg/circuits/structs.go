@@ -40,6 +40,17 @@ type Auditor struct {
 	PCT       PoseidonCiphertext
 }
 
+type Holder struct {
+	PrivateKey frontend.Variable
+	PublicKey  PublicKey
+}
+
+type OutgoingAuditor struct {
+	PrivateKey frontend.Variable
+	PublicKey  PublicKey
+	PCT        EmittedPCT
+}
+
 type MintNullifier struct {
 	ChainID       frontend.Variable `gnark:",public"`
 	NullifierHash frontend.Variable `gnark:",public"`
@@ -60,6 +71,13 @@ type PoseidonCiphertext struct {
 	Random     frontend.Variable
 }
 
+// EmittedPCT is a PCT as emitted by the contracts, decrypted with the private key instead of the randomness
+type EmittedPCT struct {
+	Ciphertext [4]frontend.Variable `gnark:",public"`
+	AuthKey    twistededwards.Point `gnark:",public"`
+	Nonce      frontend.Variable    `gnark:",public"`
+}
+
 type ElGamalCiphertext struct {
 	C1 twistededwards.Point `gnark:",public"`
 	C2 twistededwards.Point `gnark:",public"`
@@ -2,7 +2,6 @@ package circuits
 
 import (
 	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
-	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark/frontend"
 )
 
@@ -14,29 +13,38 @@ type TransferCircuit struct {
 }
 
 func (circuit *TransferCircuit) Define(api frontend.API) error {
-	// Initialize babyjub wrapper
-	babyjub := babyjub.NewBjWrapper(api, tedwards.BN254)
-
-	// Verify the transfer amount is less than or equal to the sender's balance
-	api.AssertIsLessOrEqual(circuit.ValueToTransfer, circuit.Sender.Balance)
-
-	// Verify sender's public key is well-formed
-	CheckPublicKey(api, babyjub, circuit.Sender)
-
-	// Verify sender's encrypted balance is well-formed
-	CheckBalance(api, babyjub, circuit.Sender)
-
-	// Verify sender's encrypted value is the transfer amount
-	CheckPositiveValue(api, babyjub, circuit.Sender, circuit.ValueToTransfer)
-
-	// Verify receiver's encrypted value is the transfer amount
-	CheckValue(api, babyjub, circuit.Receiver, circuit.ValueToTransfer)
-
-	// Verify receiver's encrypted summary includes the transfer amount and is encrypted with the receiver's public key
-	CheckPCTReceiver(api, babyjub, circuit.Receiver, circuit.ValueToTransfer)
-
-	// Verify auditor's encrypted summary includes the transfer amount and is encrypted with the auditor's public key
-	CheckPCTAuditor(api, babyjub, circuit.Auditor, circuit.ValueToTransfer)
+	return DefineChecks(api, circuit.Checks())
+}
 
-	return nil
+func (circuit *TransferCircuit) Checks() []Check {
+	return []Check{
+		// Verify the transfer amount is less than or equal to the sender's balance
+		{Name: "CheckSufficientBalance", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			api.AssertIsLessOrEqual(circuit.ValueToTransfer, circuit.Sender.Balance)
+		}},
+		// Verify sender's public key is well-formed
+		{Name: "CheckPublicKey", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPublicKey(api, bj, circuit.Sender)
+		}},
+		// Verify sender's encrypted balance is well-formed
+		{Name: "CheckBalance", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckBalance(api, bj, circuit.Sender)
+		}},
+		// Verify sender's encrypted value is the transfer amount
+		{Name: "CheckPositiveValue", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPositiveValue(api, bj, circuit.Sender, circuit.ValueToTransfer)
+		}},
+		// Verify receiver's encrypted value is the transfer amount
+		{Name: "CheckValue", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckValue(api, bj, circuit.Receiver, circuit.ValueToTransfer)
+		}},
+		// Verify receiver's encrypted summary includes the transfer amount and is encrypted with the receiver's public key
+		{Name: "CheckPCTReceiver", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPCTReceiver(api, bj, circuit.Receiver, circuit.ValueToTransfer)
+		}},
+		// Verify auditor's encrypted summary includes the transfer amount and is encrypted with the auditor's public key
+		{Name: "CheckPCTAuditor", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPCTAuditor(api, bj, circuit.Auditor, circuit.ValueToTransfer)
+		}},
+	}
 }
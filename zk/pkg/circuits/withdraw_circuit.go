@@ -2,7 +2,6 @@ package circuits
 
 import (
 	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
-	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark/frontend"
 )
 
@@ -12,29 +11,40 @@ type WithdrawCircuit struct {
 	ValueToBurn frontend.Variable `gnark:",public"`
 }
 
-func (circuit *WithdrawCircuit) Define(api frontend.API) error {
-	// Initialize babyjub wrapper
-	babyjub := babyjub.NewBjWrapper(api, tedwards.BN254)
-
-	// Verify the transfer amount is less than or equal to the sender's balance
-	api.AssertIsLessOrEqual(circuit.ValueToBurn, circuit.Sender.Balance)
-
-	// Verify sender's public key is well-formed
-	CheckPublicKey(api, babyjub, Sender{
-		PrivateKey: circuit.Sender.PrivateKey,
-		PublicKey:  circuit.Sender.PublicKey,
-	})
-
-	// Verify sender's encrypted balance is well-formed
-	CheckBalance(api, babyjub, Sender{
-		PrivateKey:  circuit.Sender.PrivateKey,
-		PublicKey:   circuit.Sender.PublicKey,
-		Balance:     circuit.Sender.Balance,
-		BalanceEGCT: circuit.Sender.BalanceEGCT,
-	})
+// the verifier contract reads the withdrawn amount first, see utils.ContractOrdered
+func (circuit *WithdrawCircuit) ContractOrder() []string {
+	return []string{"ValueToBurn", "Sender", "Auditor"}
+}
 
-	// Verify auditor's encrypted summary includes the burn amount and is encrypted with the auditor's public key
-	CheckPCTAuditor(api, babyjub, circuit.Auditor, circuit.ValueToBurn)
+func (circuit *WithdrawCircuit) Define(api frontend.API) error {
+	return DefineChecks(api, circuit.Checks())
+}
 
-	return nil
+func (circuit *WithdrawCircuit) Checks() []Check {
+	return []Check{
+		// Verify the transfer amount is less than or equal to the sender's balance
+		{Name: "CheckSufficientBalance", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			api.AssertIsLessOrEqual(circuit.ValueToBurn, circuit.Sender.Balance)
+		}},
+		// Verify sender's public key is well-formed
+		{Name: "CheckPublicKey", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPublicKey(api, bj, Sender{
+				PrivateKey: circuit.Sender.PrivateKey,
+				PublicKey:  circuit.Sender.PublicKey,
+			})
+		}},
+		// Verify sender's encrypted balance is well-formed
+		{Name: "CheckBalance", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckBalance(api, bj, Sender{
+				PrivateKey:  circuit.Sender.PrivateKey,
+				PublicKey:   circuit.Sender.PublicKey,
+				Balance:     circuit.Sender.Balance,
+				BalanceEGCT: circuit.Sender.BalanceEGCT,
+			})
+		}},
+		// Verify auditor's encrypted summary includes the burn amount and is encrypted with the auditor's public key
+		{Name: "CheckPCTAuditor", Run: func(api frontend.API, bj *babyjub.BjWrapper) {
+			CheckPCTAuditor(api, bj, circuit.Auditor, circuit.ValueToBurn)
+		}},
+	}
 }
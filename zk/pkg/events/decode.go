@@ -0,0 +1,251 @@
+package events
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+)
+
+// ErrUnknownEvent is returned for a log whose first topic is none of the decoded events
+var ErrUnknownEvent = errors.New("unknown event")
+
+const wordSize = 32
+
+// Log is a log object of eth_getLogs, quantities are 0x prefixed hexadecimal
+type Log struct {
+	Address         string   `json:"address"`
+	Topics          []string `json:"topics"`
+	Data            string   `json:"data"`
+	BlockNumber     string   `json:"blockNumber"`
+	TransactionHash string   `json:"transactionHash"`
+	LogIndex        string   `json:"logIndex"`
+	// set for the logs of a block removed by a reorganization
+	Removed bool `json:"removed"`
+}
+
+// ReadLogs reads a dump of eth_getLogs, either the array of logs or the whole JSON-RPC response
+//
+//	[{ "address": "0x...", "topics": ["0x..."], "data": "0x...", "blockNumber": "0x1", "transactionHash": "0x...", "logIndex": "0x0" }]
+//	{ "jsonrpc": "2.0", "id": 1, "result": [...] }
+func ReadLogs(path string) ([]Log, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []Log
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		var response struct {
+			Result *[]Log `json:"result"`
+			Error  *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(raw, &response); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if response.Error != nil {
+			return nil, fmt.Errorf("%s: the response is an error: %s", path, response.Error.Message)
+		}
+		if response.Result == nil {
+			return nil, fmt.Errorf("%s: the response has no result", path)
+		}
+		logs = *response.Result
+	} else if err := json.Unmarshal(raw, &logs); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return logs, nil
+}
+
+// DecodeLogs decodes the logs of the known events in chronological order, the logs of other events and
+// the removed logs are skipped. A known event that fails to decode is an error, it means the dump
+// does not come from the contracts.
+func DecodeLogs(logs []Log) ([]Event, error) {
+	var events []Event
+	for i := range logs {
+		if logs[i].Removed {
+			continue
+		}
+		event, err := Decode(&logs[i])
+		if errors.Is(err, ErrUnknownEvent) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("log %d (tx %s): %w", i, logs[i].TransactionHash, err)
+		}
+		events = append(events, event)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i].Metadata(), events[j].Metadata()
+		if a.BlockNumber != b.BlockNumber {
+			return a.BlockNumber < b.BlockNumber
+		}
+		return a.LogIndex < b.LogIndex
+	})
+	return events, nil
+}
+
+// Decode decodes the log of one of the events, it returns ErrUnknownEvent for other logs
+func Decode(log *Log) (Event, error) {
+	if len(log.Topics) == 0 {
+		return nil, ErrUnknownEvent
+	}
+	name, ok := topics[strings.ToLower(log.Topics[0])]
+	if !ok {
+		return nil, ErrUnknownEvent
+	}
+
+	meta, err := newMeta(name, log)
+	if err != nil {
+		return nil, err
+	}
+	d, err := newDecoder(log)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var event Event
+	switch name {
+	case "Register":
+		e := &Register{Meta: meta, User: d.address(1)}
+		if x, y := d.word(0), d.word(1); d.err == nil {
+			e.PublicKey, d.err = babyjub.NewPoint(x, y)
+		}
+		event = e
+	case "PrivateMint":
+		event = &PrivateMint{Meta: meta, User: d.address(1), AuditorPCT: d.pct(0), Auditor: d.address(2)}
+	case "PrivateBurn":
+		event = &PrivateBurn{Meta: meta, User: d.address(1), AuditorPCT: d.pct(0), Auditor: d.address(2)}
+	case "PrivateTransfer":
+		event = &PrivateTransfer{Meta: meta, From: d.address(1), To: d.address(2), AuditorPCT: d.pct(0), Auditor: d.address(3)}
+	case "Deposit":
+		event = &Deposit{Meta: meta, User: d.address(1), Amount: d.word(0), Dust: d.word(1), TokenID: d.word(2)}
+	case "Withdraw":
+		event = &Withdraw{Meta: meta, User: d.address(1), Amount: d.word(0), TokenID: d.word(1), AuditorPCT: d.pct(2), Auditor: d.address(2)}
+	case "AuditorChanged":
+		event = &AuditorChanged{Meta: meta, OldAuditor: d.address(1), NewAuditor: d.address(2)}
+	}
+
+	if err := d.finish(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return event, nil
+}
+
+func newMeta(name string, log *Log) (Meta, error) {
+	block, err := parseQuantity(log.BlockNumber)
+	if err != nil {
+		return Meta{}, fmt.Errorf("blockNumber: %w", err)
+	}
+	index, err := parseQuantity(log.LogIndex)
+	if err != nil {
+		return Meta{}, fmt.Errorf("logIndex: %w", err)
+	}
+	return Meta{
+		Event:       name,
+		Contract:    strings.ToLower(log.Address),
+		BlockNumber: block,
+		TxHash:      strings.ToLower(log.TransactionHash),
+		LogIndex:    index,
+	}, nil
+}
+
+// reads the indexed topics and the words of the data of a log, the first error is kept
+// and every word of the data must be read
+type decoder struct {
+	topics [][]byte
+	data   []byte
+	read   int
+	err    error
+}
+
+func newDecoder(log *Log) (*decoder, error) {
+	d := &decoder{}
+	for i, topic := range log.Topics {
+		b, err := decodeHex(topic)
+		if err != nil || len(b) != wordSize {
+			return nil, fmt.Errorf("topic %d is not a 32 bytes word", i)
+		}
+		d.topics = append(d.topics, b)
+	}
+
+	data, err := decodeHex(log.Data)
+	if err != nil {
+		return nil, fmt.Errorf("data: %w", err)
+	}
+	d.data = data
+	return d, nil
+}
+
+// the indexed address in topic i, lowercase 0x prefixed
+func (d *decoder) address(i int) string {
+	if d.err != nil {
+		return ""
+	}
+	if i >= len(d.topics) {
+		d.err = fmt.Errorf("missing topic %d", i)
+		return ""
+	}
+	topic := d.topics[i]
+	for _, b := range topic[:wordSize-20] {
+		if b != 0 {
+			d.err = fmt.Errorf("topic %d is not an address", i)
+			return ""
+		}
+	}
+	return "0x" + hex.EncodeToString(topic[wordSize-20:])
+}
+
+// the uint256 at word i of the data
+func (d *decoder) word(i int) *big.Int {
+	if d.err != nil {
+		return nil
+	}
+	end := (i + 1) * wordSize
+	if end > len(d.data) {
+		d.err = fmt.Errorf("data has %d bytes, expected at least %d", len(d.data), end)
+		return nil
+	}
+	d.read = max(d.read, end)
+	return new(big.Int).SetBytes(d.data[i*wordSize : end])
+}
+
+// the uint256[7] starting at word i of the data
+func (d *decoder) pct(i int) AuditorPCT {
+	var pct AuditorPCT
+	for j := range pct {
+		pct[j] = d.word(i + j)
+	}
+	return pct
+}
+
+func (d *decoder) finish() error {
+	if d.err == nil && d.read != len(d.data) {
+		return fmt.Errorf("data has %d bytes, expected %d", len(d.data), d.read)
+	}
+	return d.err
+}
+
+func decodeHex(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return nil, fmt.Errorf("%q is not 0x prefixed", s)
+	}
+	return hex.DecodeString(s[2:])
+}
+
+func parseQuantity(s string) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return 0, fmt.Errorf("%q is not 0x prefixed", s)
+	}
+	return strconv.ParseUint(s[2:], 16, 64)
+}
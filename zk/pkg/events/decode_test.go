@@ -0,0 +1,260 @@
+package events
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+)
+
+/*
+testdata/logs.json is an eth_getLogs response as a node returns it, the topics and data encoded with go-ethereum's
+ABI encoder (accounts/abi) from the event declarations of the contracts, and the auditor PCTs encrypted with NewPCT:
+
+	0x10 - 0x12  Register of auditor A (key 1111), alice (key 2222) and bob (key 3333)
+	0x13         AuditorChanged from the zero address to auditor A
+	0x14         ERC20 Transfer of alice to the contract and the Deposit of 1000 (dust 5, token 1) in the same transaction
+	0x15         PrivateMint to bob, auditor PCT of 300 for auditor A
+	0x16         PrivateTransfer from alice to bob, auditor PCT of 200 for auditor A
+	0x17 - 0x18  Register of auditor B (key 4444) and AuditorChanged from auditor A to auditor B
+	0x19         PrivateBurn of bob, auditor PCT of 50 for auditor B
+	0x1a         Withdraw of 100 (token 1) by alice, auditor PCT of 100 for auditor B
+*/
+const (
+	registrar = "0x5fbdb2315678afecb367f032d93f642f64180aa3"
+	eerc      = "0xe7f1725e7734ce288f8367e1bb143e90bb3f0512"
+	auditorA  = "0x70997970c51812dc3a010c7d01b50e0d17dc79c8"
+	auditorB  = "0x3c44cdddb6a900fa2b585dd299e03d12fa4293bc"
+	alice     = "0x90f79bf6eb2c4f870365e785982e1f101e93b906"
+	bob       = "0x15d34aaf54267db7d7c367839aaf71a00a2c6a65"
+	zero      = "0x0000000000000000000000000000000000000000"
+)
+
+var (
+	auditorAKey = big.NewInt(1111)
+	auditorBKey = big.NewInt(4444)
+)
+
+func readFixture(t *testing.T) []Event {
+	t.Helper()
+	logs, err := ReadLogs(filepath.Join("testdata", "logs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 12 {
+		t.Fatalf("expected 12 logs, got %d", len(logs))
+	}
+	events, err := DecodeLogs(logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func assertPublicKey(t *testing.T, name string, p *babyjub.Point, privateKey int64) {
+	t.Helper()
+	expected, err := babyjub.PublicKey(big.NewInt(privateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil || !p.Equal(&expected) {
+		t.Fatalf("%s: expected the public key of %d, got %v", name, privateKey, p)
+	}
+}
+
+// the auditor PCT must decrypt to the amount with the auditor key, which checks the order of its words
+func assertPCT(t *testing.T, name string, pct AuditorPCT, auditorKey *big.Int, amount int64) {
+	t.Helper()
+	parsed, err := pct.PCT()
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	decrypted, err := parsed.Decrypt(auditorKey, 1)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if decrypted[0].Int64() != amount {
+		t.Fatalf("%s: expected amount %d, got %s", name, amount, decrypted[0])
+	}
+}
+
+func TestDecodeFixture(t *testing.T) {
+	events := readFixture(t)
+
+	// the ERC20 Transfer is skipped
+	expected := []string{"Register", "Register", "Register", "AuditorChanged", "Deposit", "PrivateMint", "PrivateTransfer", "Register", "AuditorChanged", "PrivateBurn", "Withdraw"}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(events))
+	}
+	for i, e := range events {
+		if e.Metadata().Event != expected[i] {
+			t.Fatalf("event %d: expected %s, got %s", i, expected[i], e.Metadata().Event)
+		}
+	}
+
+	register := events[1].(*Register)
+	if register.User != alice || register.Contract != registrar || register.BlockNumber != 0x11 || register.LogIndex != 0 {
+		t.Fatalf("unexpected Register %+v", register)
+	}
+	assertPublicKey(t, "Register", &register.PublicKey, 2222)
+
+	changed := events[8].(*AuditorChanged)
+	if changed.OldAuditor != auditorA || changed.NewAuditor != auditorB || changed.Contract != eerc {
+		t.Fatalf("unexpected AuditorChanged %+v", changed)
+	}
+
+	deposit := events[4].(*Deposit)
+	if deposit.User != alice || deposit.Amount.Int64() != 1000 || deposit.Dust.Int64() != 5 || deposit.TokenID.Int64() != 1 || deposit.LogIndex != 1 {
+		t.Fatalf("unexpected Deposit %+v", deposit)
+	}
+
+	mint := events[5].(*PrivateMint)
+	if mint.User != bob || mint.Auditor != auditorA {
+		t.Fatalf("unexpected PrivateMint %+v", mint)
+	}
+	assertPCT(t, "PrivateMint", mint.AuditorPCT, auditorAKey, 300)
+
+	transfer := events[6].(*PrivateTransfer)
+	if transfer.From != alice || transfer.To != bob || transfer.Auditor != auditorA {
+		t.Fatalf("unexpected PrivateTransfer %+v", transfer)
+	}
+	assertPCT(t, "PrivateTransfer", transfer.AuditorPCT, auditorAKey, 200)
+
+	burn := events[9].(*PrivateBurn)
+	if burn.User != bob || burn.Auditor != auditorB {
+		t.Fatalf("unexpected PrivateBurn %+v", burn)
+	}
+	assertPCT(t, "PrivateBurn", burn.AuditorPCT, auditorBKey, 50)
+
+	withdraw := events[10].(*Withdraw)
+	if withdraw.User != alice || withdraw.Amount.Int64() != 100 || withdraw.TokenID.Int64() != 1 || withdraw.Auditor != auditorB {
+		t.Fatalf("unexpected Withdraw %+v", withdraw)
+	}
+	assertPCT(t, "Withdraw", withdraw.AuditorPCT, auditorBKey, 100)
+}
+
+func TestDecodeLogsOrder(t *testing.T) {
+	logs, err := ReadLogs(filepath.Join("testdata", "logs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the logs of a block removed by a reorganization are skipped, the others are ordered by block and log index
+	removed := logs[6]
+	removed.Removed = true
+	reversed := []Log{removed}
+	for i := len(logs) - 1; i >= 0; i-- {
+		reversed = append(reversed, logs[i])
+	}
+
+	events, err := DecodeLogs(reversed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 11 {
+		t.Fatalf("expected 11 events, got %d", len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		a, b := events[i-1].Metadata(), events[i].Metadata()
+		if a.BlockNumber > b.BlockNumber || (a.BlockNumber == b.BlockNumber && a.LogIndex >= b.LogIndex) {
+			t.Fatalf("events %d and %d are not in chronological order", i-1, i)
+		}
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	logs, err := ReadLogs(filepath.Join("testdata", "logs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mint := logs[6]
+
+	tests := []struct {
+		name   string
+		modify func(l *Log)
+	}{
+		{"missing data word", func(l *Log) { l.Data = l.Data[:len(l.Data)-64] }},
+		{"extra data word", func(l *Log) { l.Data += strings.Repeat("0", 64) }},
+		{"missing topic", func(l *Log) { l.Topics = l.Topics[:2] }},
+		{"topic not an address", func(l *Log) { l.Topics[1] = "0x01" + l.Topics[1][4:] }},
+		{"short topic", func(l *Log) { l.Topics[1] = l.Topics[1][:10] }},
+		{"data not hexadecimal", func(l *Log) { l.Data = "0xzz" }},
+		{"block number not a quantity", func(l *Log) { l.BlockNumber = "21" }},
+	}
+	for _, tt := range tests {
+		l := mint
+		l.Topics = append([]string(nil), mint.Topics...)
+		tt.modify(&l)
+		if _, err := Decode(&l); err == nil || errors.Is(err, ErrUnknownEvent) {
+			t.Errorf("%s: expected a decoding error, got %v", tt.name, err)
+		}
+		if _, err := DecodeLogs([]Log{l}); err == nil {
+			t.Errorf("%s: expected DecodeLogs to fail", tt.name)
+		}
+	}
+
+	// a public key off the curve
+	register := logs[0]
+	register.Data = "0x" + strings.Repeat("0", 63) + "1" + strings.Repeat("0", 63) + "1"
+	if _, err := Decode(&register); err == nil {
+		t.Error("expected an error for a public key off the curve")
+	}
+
+	// the topics are compared in lowercase, other events are unknown
+	upper := mint
+	upper.Topics = append([]string{"0x" + strings.ToUpper(mint.Topics[0][2:])}, mint.Topics[1:]...)
+	if _, err := Decode(&upper); err != nil {
+		t.Errorf("expected an uppercase topic to decode, got %v", err)
+	}
+	if _, err := Decode(&logs[4]); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("expected the ERC20 Transfer to be unknown, got %v", err)
+	}
+}
+
+func TestTopics(t *testing.T) {
+	// the topics go-ethereum computes for the event declarations of the contracts
+	expected := map[string]string{
+		"Register":        "0xa29f706235c83d457380cf21ecc4ba909fa846879eea28d1b12e4e3b82aa7590",
+		"PrivateMint":     "0x0d78494055b7f1585d1e50d778838efd294162edeaf41569996739183722254b",
+		"PrivateBurn":     "0x047fabd75c92ead101dbbb10dd37a09c205f633da9db8ce7078c2f33e84710e5",
+		"PrivateTransfer": "0x1fe42c57a12ee7d4848276c111f82c24fe213a94a603b21da88785cd882c9ccf",
+		"Deposit":         "0x36af321ec8d3c75236829c5317affd40ddb308863a1236d2d277a4025cccee1e",
+		"Withdraw":        "0xae09dce9b789cf9600e6765940d134d8247429396faf72db0f7b33ed5ca8294c",
+		"AuditorChanged":  "0xdbeeb2970745c839058876b084c0d772566ff6b5aaa47938c394cd171a38c24c",
+	}
+	for name, topic := range expected {
+		if got := Topic(signatures[name]); got != topic {
+			t.Errorf("%s: expected topic %s, got %s", name, topic, got)
+		}
+	}
+}
+
+func TestReadLogs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	logs, err := ReadLogs(write("array.json", `[{"address": "0x01", "topics": [], "data": "0x", "blockNumber": "0x1", "logIndex": "0x0"}]`))
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("expected 1 log from an array, got %v, %v", logs, err)
+	}
+
+	for name, content := range map[string]string{
+		"error.json":     `{"jsonrpc": "2.0", "id": 1, "error": {"code": -32005, "message": "query returned more than 10000 results"}}`,
+		"no-result.json": `{"jsonrpc": "2.0", "id": 1}`,
+		"invalid.json":   `[{"topics": 1}]`,
+	} {
+		if _, err := ReadLogs(write(name, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package events decodes the logs of the EncryptedERC and Registrar contracts from eth_getLogs dumps,
+// entirely offline, and orders them into per-user histories
+package events
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+	"golang.org/x/crypto/sha3"
+)
+
+// Event is a decoded log, one of the types below
+type Event interface {
+	Metadata() *Meta
+	// addresses of the users the event belongs to, in lowercase
+	Users() []string
+}
+
+// Meta locates the log an event was decoded from
+type Meta struct {
+	Event       string `json:"event"`
+	Contract    string `json:"contract"`
+	BlockNumber uint64 `json:"blockNumber"`
+	TxHash      string `json:"txHash"`
+	LogIndex    uint64 `json:"logIndex"`
+}
+
+func (m *Meta) Metadata() *Meta {
+	return m
+}
+
+// AuditorPCT is the uint256[7] auditor PCT of the events, ciphertext || authKey || nonce
+type AuditorPCT [7]*big.Int
+
+// PCT parses the auditor PCT, the auth key must be on the curve
+func (a AuditorPCT) PCT() (*poseidon.PCT, error) {
+	return poseidon.ParsePCT(a[:])
+}
+
+// MarshalJSON encodes the elements as decimal strings like the circuit inputs
+func (a AuditorPCT) MarshalJSON() ([]byte, error) {
+	return json.Marshal(decimals(a[:]...))
+}
+
+// Register is emitted by the Registrar when a user registers the public key
+type Register struct {
+	Meta
+	User      string        `json:"user"`
+	PublicKey babyjub.Point `json:"publicKey"`
+}
+
+// PrivateMint is emitted when the amount encrypted in the auditor PCT is minted to the user
+type PrivateMint struct {
+	Meta
+	User       string     `json:"user"`
+	AuditorPCT AuditorPCT `json:"auditorPCT"`
+	Auditor    string     `json:"auditor"`
+}
+
+// PrivateBurn is emitted when the user burns the amount encrypted in the auditor PCT
+type PrivateBurn struct {
+	Meta
+	User       string     `json:"user"`
+	AuditorPCT AuditorPCT `json:"auditorPCT"`
+	Auditor    string     `json:"auditor"`
+}
+
+// PrivateTransfer is emitted when the amount encrypted in the auditor PCT is transferred
+type PrivateTransfer struct {
+	Meta
+	From       string     `json:"from"`
+	To         string     `json:"to"`
+	AuditorPCT AuditorPCT `json:"auditorPCT"`
+	Auditor    string     `json:"auditor"`
+}
+
+// Deposit is emitted when the user converts ERC20 tokens, the amount is public
+type Deposit struct {
+	Meta
+	User    string   `json:"user"`
+	Amount  *big.Int `json:"amount"`
+	Dust    *big.Int `json:"dust"`
+	TokenID *big.Int `json:"tokenId"`
+}
+
+// Withdraw is emitted when the user converts back to ERC20 tokens, the amount is public
+type Withdraw struct {
+	Meta
+	User       string     `json:"user"`
+	Amount     *big.Int   `json:"amount"`
+	TokenID    *big.Int   `json:"tokenId"`
+	AuditorPCT AuditorPCT `json:"auditorPCT"`
+	Auditor    string     `json:"auditor"`
+}
+
+// MarshalJSON encodes the amounts as decimal strings, they may not fit a JSON number
+func (e *Deposit) MarshalJSON() ([]byte, error) {
+	type deposit Deposit
+	v := decimals(e.Amount, e.Dust, e.TokenID)
+	return json.Marshal(struct {
+		*deposit
+		Amount  string `json:"amount"`
+		Dust    string `json:"dust"`
+		TokenID string `json:"tokenId"`
+	}{(*deposit)(e), v[0], v[1], v[2]})
+}
+
+// MarshalJSON encodes the amounts as decimal strings, they may not fit a JSON number
+func (e *Withdraw) MarshalJSON() ([]byte, error) {
+	type withdraw Withdraw
+	v := decimals(e.Amount, e.TokenID)
+	return json.Marshal(struct {
+		*withdraw
+		Amount  string `json:"amount"`
+		TokenID string `json:"tokenId"`
+	}{(*withdraw)(e), v[0], v[1]})
+}
+
+// AuditorChanged is emitted when the auditor is replaced, the PCTs emitted after it are encrypted for the new one
+type AuditorChanged struct {
+	Meta
+	OldAuditor string `json:"oldAuditor"`
+	NewAuditor string `json:"newAuditor"`
+}
+
+func (e *Register) Users() []string        { return []string{e.User} }
+func (e *PrivateMint) Users() []string     { return []string{e.User} }
+func (e *PrivateBurn) Users() []string     { return []string{e.User} }
+func (e *PrivateTransfer) Users() []string { return []string{e.From, e.To} }
+func (e *Deposit) Users() []string         { return []string{e.User} }
+func (e *Withdraw) Users() []string        { return []string{e.User} }
+func (e *AuditorChanged) Users() []string  { return nil }
+
+// signatures of the decoded events, the first topic of a log is the keccak256 of its signature
+var signatures = map[string]string{
+	"Register":        "Register(address,(uint256,uint256))",
+	"PrivateMint":     "PrivateMint(address,uint256[7],address)",
+	"PrivateBurn":     "PrivateBurn(address,uint256[7],address)",
+	"PrivateTransfer": "PrivateTransfer(address,address,uint256[7],address)",
+	"Deposit":         "Deposit(address,uint256,uint256,uint256)",
+	"Withdraw":        "Withdraw(address,uint256,uint256,uint256[7],address)",
+	"AuditorChanged":  "AuditorChanged(address,address)",
+}
+
+// event names keyed by their topic, 0x prefixed lowercase hex
+var topics = make(map[string]string, len(signatures))
+
+func init() {
+	for name, signature := range signatures {
+		topics[Topic(signature)] = name
+	}
+}
+
+// Topic returns the topic of the event signature, e.g. Topic("Deposit(address,uint256,uint256,uint256)")
+func Topic(signature string) string {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(signature))
+	return "0x" + hex.EncodeToString(h.Sum(nil))
+}
+
+func decimals(values ...*big.Int) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		if v != nil {
+			out[i] = v.String()
+		}
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package events
+
+import (
+	"sort"
+
+	"github.com/ava-labs/EncryptedERC/pkg/audit"
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+)
+
+// History is the chronological list of the events of a user, the registered public key is the one of
+// the last Register event
+type History struct {
+	User      string         `json:"user"`
+	PublicKey *babyjub.Point `json:"publicKey,omitempty"`
+	Events    []Event        `json:"events"`
+}
+
+// Histories groups chronologically ordered events (see DecodeLogs) by user, sorted by address. A transfer
+// appears in the histories of both the sender and the receiver, AuditorChanged events belong to no user.
+func Histories(events []Event) []History {
+	byUser := make(map[string]*History)
+	for _, event := range events {
+		seen := make(map[string]bool, 2)
+		for _, user := range event.Users() {
+			// a transfer to oneself is listed once
+			if seen[user] {
+				continue
+			}
+			seen[user] = true
+
+			h, ok := byUser[user]
+			if !ok {
+				h = &History{User: user}
+				byUser[user] = h
+			}
+			h.Events = append(h.Events, event)
+			if register, ok := event.(*Register); ok {
+				key := register.PublicKey
+				h.PublicKey = &key
+			}
+		}
+	}
+
+	histories := make([]History, 0, len(byUser))
+	for _, h := range byUser {
+		histories = append(histories, *h)
+	}
+	sort.Slice(histories, func(i, j int) bool { return histories[i].User < histories[j].User })
+	return histories
+}
+
+// Transactions returns the events carrying an auditor PCT as transactions for audit.Decrypt, the sender
+// of a mint and the receiver of a burn or withdraw are left empty. An auditor PCT that can not be parsed
+// is kept with its error so the report accounts for every event.
+func Transactions(events []Event) []audit.Transaction {
+	var txs []audit.Transaction
+	for _, event := range events {
+		var tx audit.Transaction
+		var pct AuditorPCT
+		switch e := event.(type) {
+		case *PrivateMint:
+			tx, pct = audit.Transaction{Receiver: e.User}, e.AuditorPCT
+		case *PrivateBurn:
+			tx, pct = audit.Transaction{Sender: e.User}, e.AuditorPCT
+		case *PrivateTransfer:
+			tx, pct = audit.Transaction{Sender: e.From, Receiver: e.To}, e.AuditorPCT
+		case *Withdraw:
+			tx, pct = audit.Transaction{Sender: e.User}, e.AuditorPCT
+		default:
+			continue
+		}
+
+		tx.TxHash = event.Metadata().TxHash
+		parsed, err := pct.PCT()
+		if err != nil {
+			tx.Error = err.Error()
+		} else {
+			tx.PCT = parsed
+		}
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// Auditor is the auditor a transaction of Transactions(events) was emitted for
+type Auditor struct {
+	// empty if no AuditorChanged event tells, or if it disagrees with the auditor of the event
+	Address string
+	// public key the address registered last before the event, nil if the events hold none
+	PublicKey *babyjub.Point
+}
+
+// Auditors replays the AuditorChanged events and returns the auditor every transaction of Transactions(events)
+// was emitted for, in the same order. The auditor before the first AuditorChanged event is its old auditor.
+func Auditors(events []Event) []Auditor {
+	var auditor string
+	for _, event := range events {
+		if changed, ok := event.(*AuditorChanged); ok {
+			auditor = changed.OldAuditor
+			break
+		}
+	}
+
+	keys := make(map[string]babyjub.Point)
+	var auditors []Auditor
+	for _, event := range events {
+		var emittedFor string
+		switch e := event.(type) {
+		case *Register:
+			keys[e.User] = e.PublicKey
+			continue
+		case *AuditorChanged:
+			auditor = e.NewAuditor
+			continue
+		case *PrivateMint:
+			emittedFor = e.Auditor
+		case *PrivateBurn:
+			emittedFor = e.Auditor
+		case *PrivateTransfer:
+			emittedFor = e.Auditor
+		case *Withdraw:
+			emittedFor = e.Auditor
+		default:
+			continue
+		}
+
+		var a Auditor
+		if auditor == emittedFor {
+			a.Address = auditor
+		}
+		if key, ok := keys[a.Address]; ok && len(a.Address) != 0 {
+			a.PublicKey = &key
+		}
+		auditors = append(auditors, a)
+	}
+	return auditors
+}
@@ -0,0 +1,182 @@
+package events
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+)
+
+func TestHistories(t *testing.T) {
+	histories := Histories(readFixture(t))
+
+	// sorted by address, the AuditorChanged events belong to no user
+	users := []string{bob, auditorB, auditorA, alice}
+	if len(histories) != len(users) {
+		t.Fatalf("expected %d histories, got %d", len(users), len(histories))
+	}
+	expected := map[string][]string{
+		alice:    {"Register", "Deposit", "PrivateTransfer", "Withdraw"},
+		bob:      {"Register", "PrivateMint", "PrivateTransfer", "PrivateBurn"},
+		auditorA: {"Register"},
+		auditorB: {"Register"},
+	}
+	keys := map[string]int64{alice: 2222, bob: 3333, auditorA: 1111, auditorB: 4444}
+
+	for i, h := range histories {
+		if h.User != users[i] {
+			t.Fatalf("history %d: expected %s, got %s", i, users[i], h.User)
+		}
+		var names []string
+		for _, e := range h.Events {
+			names = append(names, e.Metadata().Event)
+		}
+		if !slices.Equal(names, expected[h.User]) {
+			t.Errorf("%s: expected %v, got %v", h.User, expected[h.User], names)
+		}
+		assertPublicKey(t, h.User, h.PublicKey, keys[h.User])
+	}
+
+	// a transfer to oneself is listed once
+	self := &PrivateTransfer{Meta: Meta{Event: "PrivateTransfer"}, From: alice, To: alice}
+	histories = Histories([]Event{self})
+	if len(histories) != 1 || len(histories[0].Events) != 1 {
+		t.Fatalf("expected a transfer to oneself to be listed once, got %+v", histories)
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	txs := Transactions(readFixture(t))
+
+	expected := []struct {
+		sender, receiver string
+		key              *big.Int
+		amount           int64
+	}{
+		{"", bob, auditorAKey, 300},
+		{alice, bob, auditorAKey, 200},
+		{bob, "", auditorBKey, 50},
+		{alice, "", auditorBKey, 100},
+	}
+	if len(txs) != len(expected) {
+		t.Fatalf("expected %d transactions, got %d", len(expected), len(txs))
+	}
+	for i, tx := range txs {
+		if tx.Sender != expected[i].sender || tx.Receiver != expected[i].receiver || len(tx.TxHash) != 66 {
+			t.Fatalf("transaction %d: unexpected %+v", i, tx)
+		}
+		decrypted, err := tx.PCT.Decrypt(expected[i].key, 1)
+		if err != nil {
+			t.Fatalf("transaction %d: %v", i, err)
+		}
+		if decrypted[0].Int64() != expected[i].amount {
+			t.Fatalf("transaction %d: expected amount %d, got %s", i, expected[i].amount, decrypted[0])
+		}
+	}
+
+	// an auditor PCT whose auth key is off the curve is kept with its error
+	var pct AuditorPCT
+	for i := range pct {
+		pct[i] = big.NewInt(1)
+	}
+	txs = Transactions([]Event{&PrivateMint{Meta: Meta{TxHash: "0x01"}, User: bob, AuditorPCT: pct}})
+	if len(txs) != 1 || txs[0].PCT != nil || len(txs[0].Error) == 0 {
+		t.Fatalf("expected the transaction to be kept with an error, got %+v", txs)
+	}
+}
+
+func TestAuditors(t *testing.T) {
+	events := readFixture(t)
+	auditors := Auditors(events)
+
+	// the mint and the transfer are emitted for auditor A, the burn and the withdraw for auditor B
+	expected := []struct {
+		address string
+		key     int64
+	}{
+		{auditorA, 1111},
+		{auditorA, 1111},
+		{auditorB, 4444},
+		{auditorB, 4444},
+	}
+	if len(auditors) != len(Transactions(events)) || len(auditors) != len(expected) {
+		t.Fatalf("expected %d auditors, got %d", len(expected), len(auditors))
+	}
+	for i, a := range auditors {
+		if a.Address != expected[i].address {
+			t.Fatalf("transaction %d: expected auditor %s, got %q", i, expected[i].address, a.Address)
+		}
+		assertPublicKey(t, a.Address, a.PublicKey, expected[i].key)
+	}
+}
+
+func TestAuditorsReplay(t *testing.T) {
+	keyA, err := babyjub.PublicKey(auditorAKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyB, err := babyjub.PublicKey(auditorBKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKeyA, err := babyjub.PublicKey(big.NewInt(7777))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mint := func(auditor string) Event { return &PrivateMint{User: bob, Auditor: auditor} }
+	events := []Event{
+		// before the first AuditorChanged, the auditor is its old auditor, whose key is not registered yet
+		mint(auditorA),
+		&Register{User: auditorA, PublicKey: keyA},
+		mint(auditorA),
+		// the event disagrees with the replayed auditor
+		mint(auditorB),
+		&AuditorChanged{OldAuditor: auditorA, NewAuditor: auditorB},
+		// auditor B has not registered a key yet
+		mint(auditorB),
+		&Register{User: auditorB, PublicKey: keyB},
+		&Deposit{User: alice},
+		mint(auditorB),
+		// auditor A registers another key, the earlier events keep the key registered before them
+		&Register{User: auditorA, PublicKey: newKeyA},
+		&AuditorChanged{OldAuditor: auditorB, NewAuditor: auditorA},
+		mint(auditorA),
+		&AuditorChanged{OldAuditor: auditorA, NewAuditor: zero},
+		mint(auditorA),
+	}
+
+	expected := []struct {
+		address string
+		key     *babyjub.Point
+	}{
+		{auditorA, nil},
+		{auditorA, &keyA},
+		{"", nil},
+		{auditorB, nil},
+		{auditorB, &keyB},
+		{auditorA, &newKeyA},
+		{"", nil},
+	}
+
+	auditors := Auditors(events)
+	if len(auditors) != len(expected) {
+		t.Fatalf("expected %d auditors, got %d", len(expected), len(auditors))
+	}
+	for i, a := range auditors {
+		if a.Address != expected[i].address {
+			t.Errorf("transaction %d: expected auditor %q, got %q", i, expected[i].address, a.Address)
+		}
+		if (a.PublicKey == nil) != (expected[i].key == nil) || (a.PublicKey != nil && !a.PublicKey.Equal(expected[i].key)) {
+			t.Errorf("transaction %d: expected key %v, got %v", i, expected[i].key, a.PublicKey)
+		}
+	}
+
+	// without AuditorChanged events no auditor is known
+	for i, a := range Auditors([]Event{&Register{User: auditorA, PublicKey: keyA}, mint(auditorA)}) {
+		if a.Address != "" || a.PublicKey != nil {
+			t.Errorf("transaction %d: expected no auditor, got %+v", i, a)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package hardhat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ava-labs/EncryptedERC/pkg/audit"
+	"github.com/ava-labs/EncryptedERC/pkg/events"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+)
+
+// AuditPaths returns the JSON and CSV report files written for the output, e.g. report.json and report.csv
+func AuditPaths(output string) (string, string) {
+	base := strings.TrimSuffix(output, filepath.Ext(output))
+	return base + ".json", base + ".csv"
+}
+
+/*
+AuditDecrypt decrypts the auditor PCTs of the exported transactions (see audit.ReadTransactions), or of the
+events of an eth_getLogs dump, and writes the JSON and CSV reports, the auditor's private key is read from
+the keystore or the input
+
+	{ "privateKey": "..." }
+*/
+func AuditDecrypt(pp helpers.TestingParams, transactionsPath, logsPath string) error {
+	if (len(transactionsPath) == 0) == (len(logsPath) == 0) {
+		return helpers.NewError(helpers.KindInput, "auditing", errTransactionsSource)
+	}
+	if len(pp.Output) == 0 {
+		return helpers.NewError(helpers.KindInput, "auditing", errors.New("output path is required"))
+	}
+
+	var in struct {
+		PrivateKey json.RawMessage `json:"privateKey"`
+	}
+	if len(pp.Input) != 0 {
+		if err := json.Unmarshal([]byte(pp.Input), &in); err != nil {
+			return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+		}
+	}
+	privateKey, _, err := privateKeyInput(pp, in.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	txs, err := readTransactions(transactionsPath, logsPath)
+	if err != nil {
+		return err
+	}
+
+	report, err := audit.Decrypt(privateKey, txs)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "auditing", err)
+	}
+
+	jsonPath, csvPath := AuditPaths(pp.Output)
+	if err := report.WriteJSON(jsonPath); err != nil {
+		return helpers.NewError(helpers.KindIO, "writing report", err)
+	}
+	if err := report.WriteCSV(csvPath); err != nil {
+		return helpers.NewError(helpers.KindIO, "writing report", err)
+	}
+
+	fmt.Printf("%d transactions, %d decrypted, %d failed, total %s\n", report.Transactions, report.Decrypted, report.Failed, report.TotalAmount)
+	return nil
+}
+
+var errTransactionsSource = errors.New("either the transactions export or the logs dump is required")
+
+// reads the transactions carrying auditor PCTs from the export or from the events of the logs dump
+func readTransactions(transactionsPath, logsPath string) ([]audit.Transaction, error) {
+	if len(logsPath) != 0 {
+		decoded, err := readEvents(logsPath)
+		if err != nil {
+			return nil, err
+		}
+		return events.Transactions(decoded), nil
+	}
+
+	txs, err := audit.ReadTransactions(transactionsPath)
+	if err != nil {
+		return nil, helpers.NewError(helpers.KindInput, "reading transactions", err)
+	}
+	return txs, nil
+}
@@ -0,0 +1,72 @@
+package hardhat
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+)
+
+// BSGSTable precomputes the 2^log2Size baby steps used to decode balances and saves them to the output file
+func BSGSTable(output string, log2Size int) error {
+	if len(output) == 0 {
+		return helpers.NewError(helpers.KindInput, "generating table", errors.New("output path is required"))
+	}
+	if log2Size < 1 || log2Size > 32 {
+		return helpers.NewError(helpers.KindInput, "generating table", errors.New("table size must be between 2^1 and 2^32"))
+	}
+
+	table, err := babyjub.NewTable(uint64(1) << log2Size)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "generating table", err)
+	}
+	if err := table.Save(output); err != nil {
+		return helpers.NewError(helpers.KindIO, "saving table", err)
+	}
+
+	fmt.Printf("table of 2^%d baby steps written to %s\n", log2Size, output)
+	return nil
+}
+
+// DefaultMaxBalance is the default bound of the decoded balances and amounts, 2^40 token units,
+// the default 2^20 table balances memory and search time for this bound
+const DefaultMaxBalance = 1 << 40
+
+// TableParams locates the BSGS table decrypting the balances and amounts that are not given in the inputs
+type TableParams struct {
+	Path string
+	// values are decoded up to MaxValue, the search takes MaxValue/size giant steps
+	MaxValue uint64
+}
+
+// decrypts the balance with the table
+func decryptBalance(tp TableParams, privateKey *big.Int, balance *babyjub.Ciphertext) (*big.Int, error) {
+	return decryptValue(tp, "balance", privateKey, balance)
+}
+
+// decrypts the named value with the table
+func decryptValue(tp TableParams, name string, privateKey *big.Int, ct *babyjub.Ciphertext) (*big.Int, error) {
+	if len(tp.Path) == 0 {
+		return nil, helpers.NewError(helpers.KindInput, "decrypting "+name, fmt.Errorf("the %s or a -table to decrypt it is required", name))
+	}
+	if tp.MaxValue == 0 {
+		return nil, helpers.NewError(helpers.KindInput, "decrypting "+name, errors.New("the maximum value must be positive"))
+	}
+
+	table, err := babyjub.OpenTable(tp.Path)
+	if err != nil {
+		return nil, helpers.NewError(helpers.KindIO, "opening table", err)
+	}
+	defer table.Close()
+
+	value, err := table.DecryptValue(privateKey, ct, tp.MaxValue)
+	if errors.Is(err, babyjub.ErrOutOfRange) {
+		return nil, helpers.NewError(helpers.KindInput, "decrypting "+name, fmt.Errorf("%w, the %s is above %d, raise -max-balance", err, name, tp.MaxValue))
+	}
+	if err != nil {
+		return nil, helpers.NewError(helpers.KindInput, "decrypting "+name, err)
+	}
+	return new(big.Int).SetUint64(value), nil
+}
@@ -0,0 +1,7 @@
+package hardhat
+
+import "github.com/ava-labs/EncryptedERC/pkg/helpers"
+
+func Burn(pp helpers.TestingParams) error {
+	return prove(pp, "BURN")
+}
@@ -0,0 +1,211 @@
+package hardhat
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/EncryptedERC/pkg/ceremony"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
+	"github.com/consensys/gnark-crypto/ecc"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// CeremonyParams holds the inputs of the phase-1 and phase-2 ceremony operations
+type CeremonyParams struct {
+	Circuit string
+	Dir     string
+	// directory of the phase-1 ceremony the phase-2 ceremonies start from
+	Phase1Dir string
+	// snarkjs .ptau file of an external phase-1 ceremony
+	Ptau string
+	// the phase-1 SRS supports circuits of up to 2^Power constraints
+	Power       int
+	Participant string
+	// hex encoded public randomness, e.g. a future block hash
+	Beacon           string
+	BeaconIterations int
+}
+
+func (cp CeremonyParams) circuitName() (string, error) {
+	name := strings.ToUpper(cp.Circuit)
+	if _, ok := Circuits[name]; !ok {
+		return "", helpers.NewError(helpers.KindInput, "ceremony", fmt.Errorf("unknown circuit %q", cp.Circuit))
+	}
+	return name, nil
+}
+
+// Phase1Init starts the phase-1 ceremony in the directory
+func Phase1Init(cp CeremonyParams) error {
+	t, err := ceremony.InitPhase1(cp.Dir, cp.Power)
+	if err != nil {
+		return helpers.NewError(helpers.KindArtifact, "phase-1 init", err)
+	}
+
+	fmt.Printf("initialized phase-1 ceremony of 2^%d, initial hash %s\n", t.Power, t.Contributions[0].Hash)
+	return nil
+}
+
+// Phase1Import imports the SRS of an external powers of tau ceremony from a .ptau file as a final phase-1 ceremony
+func Phase1Import(cp CeremonyParams) error {
+	if len(cp.Ptau) == 0 {
+		return helpers.NewError(helpers.KindInput, "phase-1 import", errors.New("ptau file is required"))
+	}
+
+	t, err := ceremony.ImportPhase1(cp.Dir, cp.Ptau, cp.Power)
+	if err != nil {
+		return helpers.NewError(helpers.KindArtifact, "phase-1 import", err)
+	}
+
+	fmt.Printf("imported phase-1 SRS of 2^%d from %s, sha256 %s\n", t.Power, t.Import.File, t.Import.Sha256)
+	return nil
+}
+
+// Phase1Contribute adds the randomness of one participant to the phase-1 ceremony
+func Phase1Contribute(cp CeremonyParams) error {
+	if len(cp.Participant) == 0 {
+		return helpers.NewError(helpers.KindInput, "phase-1 contribute", errors.New("participant name is required"))
+	}
+
+	c, err := ceremony.ContributePhase1(cp.Dir, cp.Participant)
+	if err != nil {
+		return helpers.NewError(helpers.KindArtifact, "phase-1 contribute", err)
+	}
+
+	fmt.Printf("phase-1 contribution %d by %s, hash %s\n", c.Index, c.Participant, c.Hash)
+	return nil
+}
+
+// Phase1Beacon applies the public random beacon, closing the phase-1 ceremony for contributions
+func Phase1Beacon(cp CeremonyParams) error {
+	beacon, err := hex.DecodeString(strings.TrimPrefix(cp.Beacon, "0x"))
+	if err != nil || len(beacon) == 0 {
+		return helpers.NewError(helpers.KindInput, "phase-1 beacon", errors.New("beacon must be a non-empty hex string"))
+	}
+
+	c, err := ceremony.ApplyPhase1Beacon(cp.Dir, beacon, cp.BeaconIterations)
+	if err != nil {
+		return helpers.NewError(helpers.KindArtifact, "phase-1 beacon", err)
+	}
+
+	fmt.Printf("phase-1 beacon applied as contribution %d, hash %s\n", c.Index, c.Hash)
+	return nil
+}
+
+// Phase1Verify verifies every step of the phase-1 ceremony recorded in the transcript
+func Phase1Verify(cp CeremonyParams) error {
+	if err := ceremony.VerifyPhase1(cp.Dir); err != nil {
+		return helpers.NewError(helpers.KindArtifact, "phase-1 verify", err)
+	}
+
+	fmt.Println("phase-1 ceremony verified")
+	return nil
+}
+
+// CeremonyInit compiles the circuit and derives the initial phase-2 parameters from the final phase-1 SRS
+func CeremonyInit(cp CeremonyParams) error {
+	name, err := cp.circuitName()
+	if err != nil {
+		return err
+	}
+	if len(cp.Phase1Dir) == 0 {
+		return helpers.NewError(helpers.KindInput, "ceremony init", errors.New("phase-1 ceremony directory is required"))
+	}
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, Circuits[name].New())
+	if err != nil {
+		return helpers.NewError(helpers.KindArtifact, "compiling circuit", err)
+	}
+
+	t, err := ceremony.Init(cp.Dir, name, ccs.(*cs.R1CS), cp.Phase1Dir)
+	if err != nil {
+		return helpers.NewError(helpers.KindArtifact, "ceremony init", err)
+	}
+
+	fmt.Printf("initialized %s ceremony, %d constraints, initial hash %s\n", name, t.NbConstraints, t.Contributions[0].Hash)
+	return nil
+}
+
+// CeremonyContribute adds the randomness of one participant
+func CeremonyContribute(cp CeremonyParams) error {
+	name, err := cp.circuitName()
+	if err != nil {
+		return err
+	}
+	if len(cp.Participant) == 0 {
+		return helpers.NewError(helpers.KindInput, "ceremony contribute", errors.New("participant name is required"))
+	}
+
+	c, err := ceremony.Contribute(cp.Dir, name, cp.Participant)
+	if err != nil {
+		return helpers.NewError(helpers.KindArtifact, "ceremony contribute", err)
+	}
+
+	fmt.Printf("contribution %d by %s, hash %s\n", c.Index, c.Participant, c.Hash)
+	return nil
+}
+
+// CeremonyBeacon applies the public random beacon, closing the ceremony for contributions
+func CeremonyBeacon(cp CeremonyParams) error {
+	name, err := cp.circuitName()
+	if err != nil {
+		return err
+	}
+
+	beacon, err := hex.DecodeString(strings.TrimPrefix(cp.Beacon, "0x"))
+	if err != nil || len(beacon) == 0 {
+		return helpers.NewError(helpers.KindInput, "ceremony beacon", errors.New("beacon must be a non-empty hex string"))
+	}
+
+	c, err := ceremony.ApplyBeacon(cp.Dir, name, beacon, cp.BeaconIterations)
+	if err != nil {
+		return helpers.NewError(helpers.KindArtifact, "ceremony beacon", err)
+	}
+
+	fmt.Printf("beacon applied as contribution %d, hash %s\n", c.Index, c.Hash)
+	return nil
+}
+
+// CeremonyVerify verifies every step of the ceremony recorded in the transcript, and the phase-1 ceremony again if given
+func CeremonyVerify(cp CeremonyParams) error {
+	name, err := cp.circuitName()
+	if err != nil {
+		return err
+	}
+
+	if err := ceremony.Verify(cp.Dir, name, cp.Phase1Dir); err != nil {
+		return helpers.NewError(helpers.KindArtifact, "ceremony verify", err)
+	}
+
+	fmt.Printf("%s ceremony verified\n", name)
+	return nil
+}
+
+// CeremonyFinalize verifies the ceremony and writes the final keys next to the transcript
+func CeremonyFinalize(cp CeremonyParams) error {
+	name, err := cp.circuitName()
+	if err != nil {
+		return err
+	}
+
+	ccs, pk, vk, err := ceremony.Finalize(cp.Dir, name)
+	if err != nil {
+		return helpers.NewError(helpers.KindArtifact, "ceremony finalize", err)
+	}
+
+	// the transcript records the keys as extracted, the verifying key is reordered to the contract like the keys of the setup
+	if err := utils.OrderVerifyingKey(Circuits[name].New(), vk); err != nil {
+		return helpers.NewError(helpers.KindArtifact, "ceremony finalize", err)
+	}
+
+	if err := helpers.SaveArtifacts(ccs, pk, vk, cp.Dir, name); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s keys written to %s\n", name, cp.Dir)
+	return nil
+}
@@ -0,0 +1,131 @@
+package hardhat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// CheckResult is the outcome of a single named check of the circuit
+type CheckResult struct {
+	Name string `json:"name"`
+	OK   bool   `json:"ok"`
+	// the unsatisfied constraint with the offending values, e.g. constraint #12 is not satisfied: 1 ⋅ 2 != 3
+	Error string `json:"error,omitempty"`
+}
+
+type CheckReport struct {
+	Circuit   string        `json:"circuit"`
+	Satisfied bool          `json:"satisfied"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// defines a single check of the circuit, the circuit fields are the variables of the runner so the check
+// is compiled on its own and solved with the witness of the assignment
+type checkRunner struct {
+	Circuit circuits.CheckedCircuit
+	index   int
+}
+
+func (r *checkRunner) Define(api frontend.API) error {
+	return circuits.DefineChecks(api, r.Circuit.Checks()[r.index:r.index+1])
+}
+
+// RunChecks solves every check of the assignment on its own, so a failing check does not hide the following ones
+func RunChecks(name string, assignment circuits.CheckedCircuit) *CheckReport {
+	report := &CheckReport{Circuit: name, Satisfied: true}
+
+	for i, check := range assignment.Checks() {
+		result := CheckResult{Name: check.Name, OK: true}
+		if err := solveCheck(name, assignment, i); err != nil {
+			// drop the solver trace that follows the failed constraint
+			result.OK, result.Error = false, strings.SplitN(err.Error(), "\n", 2)[0]
+			report.Satisfied = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+// compiles the check of the circuit at the index and solves it for the assignment
+func solveCheck(name string, assignment circuits.CheckedCircuit, index int) error {
+	schema, ok := Circuits[name].New().(circuits.CheckedCircuit)
+	if !ok {
+		return fmt.Errorf("circuit %s does not define named checks", name)
+	}
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &checkRunner{Circuit: schema, index: index})
+	if err != nil {
+		return err
+	}
+
+	witness, err := frontend.NewWitness(&checkRunner{Circuit: assignment, index: index}, ecc.BN254.ScalarField())
+	if err != nil {
+		return err
+	}
+	_, err = ccs.Solve(witness)
+	return err
+}
+
+// Check parses the inputs of the named circuit and runs its checks without proving
+func Check(name string, inputs Inputs) (*CheckReport, error) {
+	circuit, ok := Circuits[name]
+	if !ok {
+		return nil, helpers.NewError(helpers.KindInput, "loading circuit", fmt.Errorf("unknown circuit %s", name))
+	}
+
+	assignment, ok := circuit.New().(circuits.CheckedCircuit)
+	if !ok {
+		return nil, helpers.NewError(helpers.KindInput, "loading circuit", fmt.Errorf("circuit %s does not define named checks", name))
+	}
+
+	if _, err := inputs.Witness(assignment); err != nil {
+		return nil, helpers.NewError(helpers.KindInput, "generating witness", err)
+	}
+
+	return RunChecks(name, assignment), nil
+}
+
+// prints the outcome of every check and writes the report to the output file if given,
+// fails with a constraint error naming the failed checks
+func dryRun(pp helpers.TestingParams, name string, inputs Inputs) error {
+	report, err := Check(name, inputs)
+	if err != nil {
+		return err
+	}
+	return writeReport(pp, name, report)
+}
+
+func writeReport(pp helpers.TestingParams, name string, report *CheckReport) error {
+	var failed []string
+	for _, result := range report.Checks {
+		if result.OK {
+			fmt.Printf("ok     %s\n", result.Name)
+		} else {
+			fmt.Printf("FAILED %s: %s\n", result.Name, result.Error)
+			failed = append(failed, result.Name)
+		}
+	}
+
+	if len(pp.Output) != 0 {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return helpers.NewError(helpers.KindUnknown, "writing check report", err)
+		}
+		if err := os.WriteFile(pp.Output, out, 0644); err != nil {
+			return helpers.NewError(helpers.KindIO, "writing check report", err)
+		}
+	}
+
+	if !report.Satisfied {
+		return helpers.NewError(helpers.KindConstraint, "checking "+name, errors.New("failed "+strings.Join(failed, ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package hardhat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/builder"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Disclosure is the statement of a disclosure proof, written next to the proof as "disclosure": the ciphertext
+// (ValueEGCT or PCT) decrypts to the amount with the private key of the public key
+type Disclosure struct {
+	Circuit   string              `json:"circuit"`
+	PublicKey babyjub.Point       `json:"publicKey"`
+	ValueEGCT *babyjub.Ciphertext `json:"valueEGCT,omitempty"`
+	// uint256[7] layout of the contracts
+	PCT    []string `json:"pct,omitempty"`
+	Amount string   `json:"amount"`
+}
+
+func EGCTDisclosure(pp helpers.TestingParams) error {
+	return prove(pp, "EGCT_DISCLOSURE")
+}
+
+func PCTDisclosure(pp helpers.TestingParams) error {
+	return prove(pp, "PCT_DISCLOSURE")
+}
+
+/*
+Disclose proves the ElGamal ciphertext (e.g. the ValueEGCT of a transfer) or the PCT (e.g. the receiver PCT of
+a transfer) decrypts to its amount with the holder's key and writes the statement next to the proof as
+"disclosure". The amount of an EGCT is decrypted with the table if it is not given, the amount of a PCT is
+always decrypted. The private key is read from the keystore if there is one.
+
+	{ "privateKey": "...", "valueEGCT": { "c1": ["x", "y"], "c2": ["x", "y"] }, "amount": "..." }
+	{ "privateKey": "...", "pct": ["...", "...", "...", "...", "x", "y", "nonce"] }
+*/
+func Disclose(pp helpers.TestingParams, tp TableParams) error {
+	var in struct {
+		PrivateKey json.RawMessage     `json:"privateKey"`
+		ValueEGCT  *babyjub.Ciphertext `json:"valueEGCT"`
+		PCT        []json.RawMessage   `json:"pct"`
+		Amount     json.RawMessage     `json:"amount"`
+	}
+	if err := json.Unmarshal([]byte(pp.Input), &in); err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	if (in.ValueEGCT == nil) == (in.PCT == nil) {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("either valueEGCT or pct is required"))
+	}
+
+	privateKey, _, err := privateKeyInput(pp, in.PrivateKey)
+	if err != nil {
+		return err
+	}
+	if err := babyjub.CheckPrivateKey(privateKey); err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	publicKey, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+
+	if in.ValueEGCT != nil {
+		if in.ValueEGCT.IsZero() {
+			return helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("valueEGCT is zero"))
+		}
+
+		var amount *big.Int
+		if in.Amount != nil {
+			if amount, err = parseValue("amount", in.Amount); err != nil {
+				return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+			}
+		} else if amount, err = decryptValue(tp, "amount", privateKey, in.ValueEGCT); err != nil {
+			return err
+		}
+
+		assignment, _, err := builder.EGCTDisclosure(privateKey, in.ValueEGCT, amount)
+		if err != nil {
+			return helpers.NewError(helpers.KindInput, "building disclosure", err)
+		}
+		disclosure := Disclosure{Circuit: "EGCT_DISCLOSURE", PublicKey: publicKey, ValueEGCT: in.ValueEGCT, Amount: amount.String()}
+		return proveAssignment(pp, disclosure.Circuit, assignment, map[string]interface{}{"disclosure": disclosure})
+	}
+
+	if in.Amount != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("the amount of a pct is decrypted, it can not be given"))
+	}
+	if len(in.PCT) != 7 {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", fmt.Errorf("expected 7 pct elements, got %d", len(in.PCT)))
+	}
+	values := make([]*big.Int, len(in.PCT))
+	for i, raw := range in.PCT {
+		if values[i], err = parseValue(fmt.Sprintf("pct[%d]", i), raw); err != nil {
+			return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+		}
+	}
+	pct, err := poseidon.ParsePCT(values)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", fmt.Errorf("pct: %w", err))
+	}
+
+	assignment, _, amount, err := builder.PCTDisclosure(privateKey, pct)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "building disclosure", err)
+	}
+	disclosure := Disclosure{Circuit: "PCT_DISCLOSURE", PublicKey: publicKey, PCT: decimalStrings(pct.Values()), Amount: amount.String()}
+	return proveAssignment(pp, disclosure.Circuit, assignment, map[string]interface{}{"disclosure": disclosure})
+}
+
+/*
+VerifyDisclosure verifies a disclosure written by Disclose, given as input, against public signals rebuilt from its
+statement, so a valid disclosure proves exactly what the statement says. The verifying key is read from the vk path,
+or <dir>/<circuit>.vk. The verifier still has to check the public key is the registered one and the ciphertext is
+the one of the transaction.
+*/
+func VerifyDisclosure(pp helpers.TestingParams) (bool, error) {
+	var in struct {
+		VerifyInputs
+		Disclosure *Disclosure `json:"disclosure"`
+	}
+	if err := json.Unmarshal([]byte(pp.Input), &in); err != nil {
+		return false, helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	if in.Disclosure == nil {
+		return false, helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("missing disclosure"))
+	}
+	d := in.Disclosure
+
+	signals, err := disclosureSignals(d)
+	if err != nil {
+		return false, helpers.NewError(helpers.KindInput, "parsing disclosure", err)
+	}
+
+	vkPath := pp.VkPath
+	if len(vkPath) == 0 {
+		vkPath = filepath.Join(pp.Dir, d.Circuit+".vk")
+	}
+	vk, err := helpers.ReadVK(vkPath)
+	if err != nil {
+		return false, err
+	}
+
+	// public signals that do not match the statement mean the disclosure was altered
+	valid := in.PublicSignals == nil || slices.Equal(signals, in.PublicSignals)
+	if valid {
+		valid, err = VerifyProof(vk, in.Proof, signals)
+		if err != nil {
+			return false, helpers.NewError(helpers.KindInput, "verifying disclosure", err)
+		}
+	}
+
+	if valid {
+		fmt.Printf("valid: amount %s for public key %s\n", d.Amount, d.PublicKey)
+	} else {
+		fmt.Println("invalid")
+	}
+
+	if len(pp.Output) != 0 {
+		result, err := json.Marshal(map[string]interface{}{"valid": valid, "disclosure": d})
+		if err != nil {
+			return valid, helpers.NewError(helpers.KindUnknown, "writing result", err)
+		}
+		if err := os.WriteFile(pp.Output, result, 0644); err != nil {
+			return valid, helpers.NewError(helpers.KindIO, "writing result", err)
+		}
+	}
+	return valid, nil
+}
+
+// public signals of the disclosure proof, in the order of the circuit fields: public key, ciphertext, amount
+func disclosureSignals(d *Disclosure) ([]string, error) {
+	signals := []string{d.PublicKey.X.String(), d.PublicKey.Y.String()}
+	switch d.Circuit {
+	case "EGCT_DISCLOSURE":
+		if d.ValueEGCT == nil || d.PCT != nil {
+			return nil, errors.New("an EGCT disclosure holds the valueEGCT only")
+		}
+		ct := d.ValueEGCT
+		signals = append(signals, ct.C1.X.String(), ct.C1.Y.String(), ct.C2.X.String(), ct.C2.Y.String())
+	case "PCT_DISCLOSURE":
+		if d.PCT == nil || d.ValueEGCT != nil {
+			return nil, errors.New("a PCT disclosure holds the pct only")
+		}
+		if len(d.PCT) != 7 {
+			return nil, fmt.Errorf("expected 7 pct elements, got %d", len(d.PCT))
+		}
+		signals = append(signals, d.PCT...)
+	default:
+		return nil, fmt.Errorf("unknown disclosure circuit %q", d.Circuit)
+	}
+	signals = append(signals, d.Amount)
+
+	// the statement must be canonical decimal field elements to compare with the public signals
+	for i, s := range signals {
+		v, ok := new(big.Int).SetString(s, 10)
+		if !ok || v.Sign() < 0 || v.Cmp(ecc.BN254.ScalarField()) >= 0 || v.String() != s {
+			return nil, fmt.Errorf("public signal %d %q is not a decimal field element", i, s)
+		}
+	}
+	if nb := Circuits[d.Circuit].NbPublicSignals; len(signals) != nb {
+		return nil, fmt.Errorf("expected %d public signals, got %d", nb, len(signals))
+	}
+	return signals, nil
+}
@@ -0,0 +1,56 @@
+package hardhat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ava-labs/EncryptedERC/pkg/events"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+)
+
+// DecodedEvents is the output of DecodeEvents
+type DecodedEvents struct {
+	Events    []events.Event   `json:"events"`
+	Histories []events.History `json:"histories"`
+}
+
+// DecodeEvents decodes the EncryptedERC and Registrar events of an eth_getLogs dump (see events.ReadLogs)
+// and writes them in chronological order along with the history of every user
+func DecodeEvents(logsPath, output string) error {
+	if len(logsPath) == 0 {
+		return helpers.NewError(helpers.KindInput, "decoding events", errors.New("the logs dump is required"))
+	}
+	if len(output) == 0 {
+		return helpers.NewError(helpers.KindInput, "decoding events", errors.New("output path is required"))
+	}
+
+	decoded, err := readEvents(logsPath)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(DecodedEvents{Events: decoded, Histories: events.Histories(decoded)}, "", "  ")
+	if err != nil {
+		return helpers.NewError(helpers.KindUnknown, "decoding events", err)
+	}
+	if err := os.WriteFile(output, out, 0644); err != nil {
+		return helpers.NewError(helpers.KindIO, "writing events", err)
+	}
+
+	fmt.Printf("%d events\n", len(decoded))
+	return nil
+}
+
+func readEvents(logsPath string) ([]events.Event, error) {
+	logs, err := events.ReadLogs(logsPath)
+	if err != nil {
+		return nil, helpers.NewError(helpers.KindInput, "reading logs", err)
+	}
+	decoded, err := events.DecodeLogs(logs)
+	if err != nil {
+		return nil, helpers.NewError(helpers.KindInput, "decoding events", fmt.Errorf("%s: %w", logsPath, err))
+	}
+	return decoded, nil
+}
@@ -0,0 +1,65 @@
+package hardhat
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/snarkjs"
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
+)
+
+// SnarkjsPaths returns the snarkjs proof.json and public.json paths next to the output file,
+// e.g. out/register.json gives out/register_proof.json and out/register_public.json
+func SnarkjsPaths(output string) (string, string) {
+	base := strings.TrimSuffix(output, filepath.Ext(output))
+	return base + "_proof.json", base + "_public.json"
+}
+
+// ExportVK writes the verifying key read from the vk path as snarkjs verification_key.json to the output file
+func ExportVK(pp helpers.TestingParams) error {
+	if len(pp.VkPath) == 0 {
+		return helpers.NewError(helpers.KindInput, "loading verifying key", errors.New("verifying key path is required"))
+	}
+	if len(pp.Output) == 0 {
+		return helpers.NewError(helpers.KindInput, "exporting verifying key", errors.New("output path is required"))
+	}
+
+	vk, err := helpers.ReadVK(pp.VkPath)
+	if err != nil {
+		return err
+	}
+
+	key, err := snarkjs.ExportVerifyingKey(vk)
+	if err != nil {
+		return helpers.NewError(helpers.KindArtifact, "exporting verifying key", err)
+	}
+
+	return helpers.NewError(helpers.KindIO, "writing verifying key", snarkjs.WriteJSON(pp.Output, key))
+}
+
+// ExportProof converts a proof written by the prover, given as input, to snarkjs proof.json and public.json
+func ExportProof(pp helpers.TestingParams) error {
+	var inputs VerifyInputs
+	err := json.Unmarshal([]byte(pp.Input), &inputs)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	if len(pp.Output) == 0 {
+		return helpers.NewError(helpers.KindInput, "exporting proof", errors.New("output path is required"))
+	}
+
+	proof, err := utils.ParseProof(inputs.Proof)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing proof", err)
+	}
+
+	proofFile, publicFile := SnarkjsPaths(pp.Output)
+	err = snarkjs.WriteProof(proof, inputs.PublicSignals, proofFile, publicFile)
+	if err != nil {
+		return helpers.NewError(helpers.KindIO, "writing snarkjs proof", err)
+	}
+	return nil
+}
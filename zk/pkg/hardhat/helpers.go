@@ -1,6 +1,94 @@
 package hardhat
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+)
+
+/*
+Inputs holds the circuit inputs either positionally or keyed by circuit field path
+
+	{ "privateInputs": [...], "publicInputs": [...] }
+	{ "inputs": { "Sender.PublicKey.P.X": "...", "Auditor.PCT.Ciphertext[2]": "...", ... } }
+*/
 type Inputs struct {
-	PrivIns []string `json:"privateInputs"`
-	PubIns  []string `json:"publicInputs"`
+	PrivIns []string                   `json:"privateInputs"`
+	PubIns  []string                   `json:"publicInputs"`
+	Fields  map[string]json.RawMessage `json:"inputs"`
+}
+
+// Circuit describes a circuit proved by the prover
+type Circuit struct {
+	New func() frontend.Circuit
+	// length of publicSignals in the matching proof struct of the verifier contracts
+	NbPublicSignals int
+	// named input the -keystore key is set to, empty if the circuit takes no private key
+	PrivateKey string
+}
+
+// Circuits maps the operation names to the circuits they prove
+var Circuits = map[string]Circuit{
+	"REGISTER":          {New: func() frontend.Circuit { return &circuits.RegistrationCircuit{} }, NbPublicSignals: 5, PrivateKey: "Sender.PrivateKey"},
+	"MINT":              {New: func() frontend.Circuit { return &circuits.MintCircuit{} }, NbPublicSignals: 24},
+	"TRANSFER":          {New: func() frontend.Circuit { return &circuits.TransferCircuit{} }, NbPublicSignals: 32, PrivateKey: "Sender.PrivateKey"},
+	"WITHDRAW":          {New: func() frontend.Circuit { return &circuits.WithdrawCircuit{} }, NbPublicSignals: 16, PrivateKey: "Sender.PrivateKey"},
+	"BURN":              {New: func() frontend.Circuit { return &circuits.BurnCircuit{} }, NbPublicSignals: 19, PrivateKey: "Sender.PrivateKey"},
+	"BALANCE_THRESHOLD": {New: func() frontend.Circuit { return &circuits.BalanceThresholdCircuit{} }, NbPublicSignals: 9, PrivateKey: "Sender.PrivateKey"},
+	"EGCT_DISCLOSURE":   {New: func() frontend.Circuit { return &circuits.EGCTDisclosureCircuit{} }, NbPublicSignals: 7, PrivateKey: "Holder.PrivateKey"},
+	"PCT_DISCLOSURE":    {New: func() frontend.Circuit { return &circuits.PCTDisclosureCircuit{} }, NbPublicSignals: 10, PrivateKey: "Holder.PrivateKey"},
+	// no verifier contract, the handover bundles are verified offline
+	"AUDITOR_ROTATION": {New: func() frontend.Circuit { return &circuits.AuditorRotationCircuit{} }, NbPublicSignals: 18, PrivateKey: "OldAuditor.PrivateKey"},
+}
+
+// returns the public signals of the witness in the order of the publicSignals of the verifier contract,
+// which differs from the order gnark assigns the public fields for MINT and WITHDRAW, see utils.PublicOrder
+func (c Circuit) PublicSignals(w witness.Witness) ([]string, error) {
+	signals, err := utils.PublicSignals(c.New(), w)
+	if err != nil {
+		return nil, err
+	}
+	if len(signals) != c.NbPublicSignals {
+		return nil, fmt.Errorf("expected %d public signals, got %d", c.NbPublicSignals, len(signals))
+	}
+	return signals, nil
+}
+
+// generates the witness for the circuit from the named inputs if given,
+// otherwise falls back to the positional private and public inputs, the assignment is filled either way
+func (inputs Inputs) Witness(assignment frontend.Circuit) (witness.Witness, error) {
+	if inputs.Fields == nil {
+		w, err := utils.GenerateWitness(inputs.PubIns, inputs.PrivIns)
+		if err != nil {
+			return nil, err
+		}
+		if err := utils.AssignWitness(assignment, w); err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+
+	if len(inputs.PrivIns) > 0 || len(inputs.PubIns) > 0 {
+		return nil, errors.New("named inputs can not be combined with privateInputs/publicInputs")
+	}
+	return utils.GenerateWitnessFromFields(assignment, inputs.Fields)
+}
+
+// parses a required value of the builder inputs, see utils.ParseFieldValue
+func parseValue(name string, raw json.RawMessage) (*big.Int, error) {
+	if raw == nil {
+		return nil, fmt.Errorf("missing %s", name)
+	}
+	value, err := utils.ParseFieldValue(raw, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return value, nil
 }
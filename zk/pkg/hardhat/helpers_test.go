@@ -0,0 +1,119 @@
+package hardhat
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
+)
+
+// the public signals the contracts read, by index of the publicSignals of their proof structs
+var contractSignals = map[string]map[int]string{
+	// Registrar.register
+	"REGISTER": {
+		0: "Sender.PublicKey.P.X",
+		1: "Sender.PublicKey.P.Y",
+		2: "Sender.Address",
+		3: "Sender.ChainID",
+		4: "Sender.RegistrationHash",
+	},
+	// EncryptedERC.privateMint
+	"MINT": {
+		0:  "MintNullifier.ChainID",
+		1:  "MintNullifier.NullifierHash",
+		2:  "Receiver.PublicKey.P.X",
+		3:  "Receiver.PublicKey.P.Y",
+		4:  "Receiver.ValueEGCT.C1.X",
+		5:  "Receiver.ValueEGCT.C1.Y",
+		6:  "Receiver.ValueEGCT.C2.X",
+		7:  "Receiver.ValueEGCT.C2.Y",
+		8:  "Receiver.PCT.Ciphertext[0]",
+		14: "Receiver.PCT.Nonce",
+		15: "Auditor.PublicKey.P.X",
+		16: "Auditor.PublicKey.P.Y",
+		17: "Auditor.PCT.Ciphertext[0]",
+		23: "Auditor.PCT.Nonce",
+	},
+	// EncryptedERC.transfer
+	"TRANSFER": {
+		0:  "Sender.PublicKey.P.X",
+		1:  "Sender.PublicKey.P.Y",
+		2:  "Sender.BalanceEGCT.C1.X",
+		5:  "Sender.BalanceEGCT.C2.Y",
+		6:  "Sender.ValueEGCT.C1.X",
+		9:  "Sender.ValueEGCT.C2.Y",
+		10: "Receiver.PublicKey.P.X",
+		11: "Receiver.PublicKey.P.Y",
+		12: "Receiver.ValueEGCT.C1.X",
+		15: "Receiver.ValueEGCT.C2.Y",
+		16: "Receiver.PCT.Ciphertext[0]",
+		22: "Receiver.PCT.Nonce",
+		23: "Auditor.PublicKey.P.X",
+		24: "Auditor.PublicKey.P.Y",
+		25: "Auditor.PCT.Ciphertext[0]",
+		31: "Auditor.PCT.Nonce",
+	},
+	// EncryptedERC.withdraw
+	"WITHDRAW": {
+		0:  "ValueToBurn",
+		1:  "Sender.PublicKey.P.X",
+		2:  "Sender.PublicKey.P.Y",
+		3:  "Sender.BalanceEGCT.C1.X",
+		4:  "Sender.BalanceEGCT.C1.Y",
+		5:  "Sender.BalanceEGCT.C2.X",
+		6:  "Sender.BalanceEGCT.C2.Y",
+		7:  "Auditor.PublicKey.P.X",
+		8:  "Auditor.PublicKey.P.Y",
+		9:  "Auditor.PCT.Ciphertext[0]",
+		15: "Auditor.PCT.Nonce",
+	},
+	// EncryptedERC.privateBurn
+	"BURN": {
+		0:  "Sender.PublicKey.P.X",
+		1:  "Sender.PublicKey.P.Y",
+		2:  "Sender.BalanceEGCT.C1.X",
+		5:  "Sender.BalanceEGCT.C2.Y",
+		6:  "Sender.ValueEGCT.C1.X",
+		9:  "Sender.ValueEGCT.C2.Y",
+		10: "Auditor.PublicKey.P.X",
+		11: "Auditor.PublicKey.P.Y",
+		12: "Auditor.PCT.Ciphertext[0]",
+		18: "Auditor.PCT.Nonce",
+	},
+}
+
+func TestPublicSignalsContractOrder(t *testing.T) {
+	for name, expected := range contractSignals {
+		t.Run(name, func(t *testing.T) {
+			circuit := Circuits[name]
+
+			// every leaf gets its own value so the signals can be traced back to the fields
+			fields, err := utils.CircuitFields(circuit.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+			inputs := Inputs{Fields: make(map[string]json.RawMessage, len(fields))}
+			values := make(map[string]string, len(fields))
+			for i, field := range fields {
+				values[field.Name] = strconv.Itoa(i + 1)
+				inputs.Fields[field.Name] = json.RawMessage(strconv.Quote(values[field.Name]))
+			}
+
+			w, err := inputs.Witness(circuit.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+			signals, err := circuit.PublicSignals(w)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for index, field := range expected {
+				if signals[index] != values[field] {
+					t.Errorf("publicSignals[%d] = %s, expected %s = %s", index, signals[index], field, values[field])
+				}
+			}
+		})
+	}
+}
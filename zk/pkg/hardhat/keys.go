@@ -0,0 +1,85 @@
+package hardhat
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/keys"
+)
+
+// DerivedKey is the output of DERIVE_KEY, the names follow deriveKeysFromUser of the frontend
+type DerivedKey struct {
+	Address             string        `json:"address,omitempty"`
+	Signature           string        `json:"signature"`
+	PrivateKey          string        `json:"privateKey"`
+	FormattedPrivateKey string        `json:"formattedPrivateKey"`
+	PublicKey           babyjub.Point `json:"publicKey"`
+}
+
+/*
+DeriveKey derives the eERC key of a wallet like the frontend. With a key file the registration message is signed
+with the hexadecimal secp256k1 key it holds, otherwise the signature of the wallet is read from the input
+
+	{ "signature": "0x..." }
+
+The key is written to the output file, or printed if there is none.
+*/
+func DeriveKey(input, keyFile, output string) error {
+	var address string
+	var signature []byte
+
+	if len(keyFile) != 0 {
+		key, err := keys.ReadEthereumKey(keyFile)
+		if err != nil {
+			return helpers.NewError(helpers.KindInput, "reading key file", err)
+		}
+		address = key.Address()
+		signature = key.SignMessage(keys.RegistrationMessage(address))
+	} else {
+		var in struct {
+			Signature string `json:"signature"`
+		}
+		if len(input) != 0 {
+			if err := json.Unmarshal([]byte(input), &in); err != nil {
+				return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+			}
+		}
+		if len(in.Signature) == 0 {
+			return helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("a -key-file or the signature is required"))
+		}
+
+		var err error
+		signature, err = keys.ParseSignature(in.Signature)
+		if err != nil {
+			return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+		}
+	}
+
+	derived, err := keys.DeriveKey(signature)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "deriving key", err)
+	}
+
+	out, err := json.MarshalIndent(DerivedKey{
+		Address:             address,
+		Signature:           "0x" + hex.EncodeToString(signature),
+		PrivateKey:          derived.Seed.String(),
+		FormattedPrivateKey: derived.PrivateKey.String(),
+		PublicKey:           derived.PublicKey,
+	}, "", "  ")
+	if err != nil {
+		return helpers.NewError(helpers.KindUnknown, "writing key", err)
+	}
+
+	if len(output) == 0 {
+		fmt.Println(string(out))
+		return nil
+	}
+	// the output holds a private key
+	return helpers.NewError(helpers.KindIO, "writing key", os.WriteFile(output, out, 0600))
+}
@@ -0,0 +1,315 @@
+package hardhat
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/keys"
+)
+
+// environment variables holding the keystore passwords when no password file is given
+const (
+	passwordEnv    = "EERC_PASSWORD"
+	newPasswordEnv = "EERC_NEW_PASSWORD"
+)
+
+// KeystoreParams holds the inputs of the key management operations
+type KeystoreParams struct {
+	Input  string
+	Output string
+	// path of the keystore file
+	Keystore        string
+	PasswordFile    string
+	NewPasswordFile string
+	// hexadecimal secp256k1 key the babyjub key is derived from, see DeriveKey
+	KeyFile  string
+	LightKDF bool
+}
+
+// KeystoreInfo is the public part of a keystore written by EXPORT_PUBLIC
+type KeystoreInfo struct {
+	Address   string        `json:"address,omitempty"`
+	ChainID   string        `json:"chainId,omitempty"`
+	PublicKey babyjub.Point `json:"publicKey"`
+}
+
+// stdin is shared by the password prompts so two passwords can be piped on consecutive lines
+var stdin = bufio.NewReader(os.Stdin)
+
+// reads the password from the file, the environment variable or the next line of stdin in this order,
+// passwords are never read from the command line where they would show in process listings
+func readPassword(file, env, prompt string) ([]byte, error) {
+	if len(file) != 0 {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strings.TrimRight(string(raw), "\r\n")), nil
+	}
+	if password, ok := os.LookupEnv(env); ok {
+		return []byte(password), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return nil, errors.New("no password: use a password file, $" + env + " or stdin")
+	}
+	return []byte(strings.TrimRight(line, "\r\n")), nil
+}
+
+func (kp KeystoreParams) scrypt() keys.ScryptParams {
+	if kp.LightKDF {
+		return keys.LightScrypt
+	}
+	return keys.StandardScrypt
+}
+
+/*
+Keygen creates a keystore holding a new babyjub key, generated randomly or derived from the key file like the frontend
+derives it from the wallet, in which case the address of the wallet is recorded. The input optionally links the key
+
+	{ "address": "0x...", "chainId": "43113" }
+*/
+func Keygen(kp KeystoreParams) error {
+	in, err := parseKeystoreInput(kp.Input)
+	if err != nil {
+		return err
+	}
+
+	var privateKey *big.Int
+	if len(kp.KeyFile) != 0 {
+		wallet, err := keys.ReadEthereumKey(kp.KeyFile)
+		if err != nil {
+			return helpers.NewError(helpers.KindInput, "reading key file", err)
+		}
+		derived, err := keys.DeriveKey(wallet.SignMessage(keys.RegistrationMessage(wallet.Address())))
+		if err != nil {
+			return helpers.NewError(helpers.KindInput, "deriving key", err)
+		}
+		if len(in.Address) == 0 {
+			in.Address = wallet.Address()
+		}
+		privateKey = derived.PrivateKey
+	} else {
+		if privateKey, err = babyjub.GenerateKey(nil); err != nil {
+			return helpers.NewError(helpers.KindUnknown, "generating key", err)
+		}
+	}
+
+	return saveKeystore(kp, privateKey, in)
+}
+
+/*
+Import stores an existing babyjub key, or the key derived from a wallet signature, in a new keystore
+
+	{ "privateKey": "...", "address": "0x...", "chainId": "43113" }
+	{ "signature": "0x...", "address": "0x...", "chainId": "43113" }
+*/
+func Import(kp KeystoreParams) error {
+	in, err := parseKeystoreInput(kp.Input)
+	if err != nil {
+		return err
+	}
+
+	var privateKey *big.Int
+	switch {
+	case in.PrivateKey != nil && len(in.Signature) != 0:
+		return helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("give either the private key or the signature"))
+	case in.PrivateKey != nil:
+		if privateKey, err = parseValue("privateKey", in.PrivateKey); err != nil {
+			return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+		}
+	case len(in.Signature) != 0:
+		signature, err := keys.ParseSignature(in.Signature)
+		if err != nil {
+			return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+		}
+		derived, err := keys.DeriveKey(signature)
+		if err != nil {
+			return helpers.NewError(helpers.KindInput, "deriving key", err)
+		}
+		privateKey = derived.PrivateKey
+	default:
+		return helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("the private key or a signature is required"))
+	}
+
+	return saveKeystore(kp, privateKey, in)
+}
+
+// ExportPublic writes the public key with the linked address and chain id, no password is needed
+func ExportPublic(kp KeystoreParams) error {
+	ks, err := readKeystore(kp.Keystore)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(KeystoreInfo{Address: ks.Address, ChainID: ks.ChainID, PublicKey: ks.PublicKey}, "", "  ")
+	if err != nil {
+		return helpers.NewError(helpers.KindUnknown, "exporting public key", err)
+	}
+	if len(kp.Output) == 0 {
+		fmt.Println(string(out))
+		return nil
+	}
+	return helpers.NewError(helpers.KindIO, "exporting public key", os.WriteFile(kp.Output, out, 0644))
+}
+
+// ChangePassword re-encrypts the keystore with the new password
+func ChangePassword(kp KeystoreParams) error {
+	ks, err := readKeystore(kp.Keystore)
+	if err != nil {
+		return err
+	}
+
+	oldPassword, err := readPassword(kp.PasswordFile, passwordEnv, "Current password: ")
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "reading password", err)
+	}
+	newPassword, err := readPassword(kp.NewPasswordFile, newPasswordEnv, "New password: ")
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "reading password", err)
+	}
+	if len(newPassword) == 0 {
+		return helpers.NewError(helpers.KindInput, "changing password", errors.New("the new password is empty"))
+	}
+
+	if err := ks.ChangePassword(oldPassword, newPassword, kp.scrypt()); err != nil {
+		return helpers.NewError(helpers.KindInput, "changing password", err)
+	}
+	if err := ks.Replace(kp.Keystore); err != nil {
+		return helpers.NewError(helpers.KindIO, "saving keystore", err)
+	}
+
+	fmt.Printf("password of %s changed\n", kp.Keystore)
+	return nil
+}
+
+type keystoreInput struct {
+	PrivateKey json.RawMessage `json:"privateKey"`
+	Signature  string          `json:"signature"`
+	Address    string          `json:"address"`
+	ChainID    json.RawMessage `json:"chainId"`
+}
+
+func parseKeystoreInput(input string) (*keystoreInput, error) {
+	var in keystoreInput
+	if len(input) == 0 {
+		return &in, nil
+	}
+	if err := json.Unmarshal([]byte(input), &in); err != nil {
+		return nil, helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	return &in, nil
+}
+
+func saveKeystore(kp KeystoreParams, privateKey *big.Int, in *keystoreInput) error {
+	if len(kp.Keystore) == 0 {
+		return helpers.NewError(helpers.KindInput, "saving keystore", errors.New("keystore path is required"))
+	}
+
+	var chainID *big.Int
+	if in.ChainID != nil {
+		var err error
+		if chainID, err = parseValue("chainId", in.ChainID); err != nil {
+			return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+		}
+	}
+
+	password, err := readPassword(kp.PasswordFile, passwordEnv, "Password: ")
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "reading password", err)
+	}
+	if len(password) == 0 {
+		return helpers.NewError(helpers.KindInput, "saving keystore", errors.New("the password is empty"))
+	}
+
+	ks, err := keys.EncryptKey(privateKey, in.Address, chainID, password, kp.scrypt())
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "encrypting key", err)
+	}
+	if err := ks.Save(kp.Keystore); err != nil {
+		return helpers.NewError(helpers.KindIO, "saving keystore", err)
+	}
+
+	fmt.Printf("keystore written to %s, public key %s\n", kp.Keystore, ks.PublicKey)
+	return nil
+}
+
+func readKeystore(path string) (*keys.Keystore, error) {
+	if len(path) == 0 {
+		return nil, helpers.NewError(helpers.KindInput, "reading keystore", errors.New("keystore path is required"))
+	}
+	ks, err := keys.ReadKeystore(path)
+	if err != nil {
+		return nil, helpers.NewError(helpers.KindInput, "reading keystore", err)
+	}
+	return ks, nil
+}
+
+// decrypts the keystore the prover was given, the password is read like for the key management operations
+func unlockKeystore(path, passwordFile string) (*big.Int, *keys.Keystore, error) {
+	ks, err := readKeystore(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	password, err := readPassword(passwordFile, passwordEnv, "Password: ")
+	if err != nil {
+		return nil, nil, helpers.NewError(helpers.KindInput, "reading password", err)
+	}
+	privateKey, err := ks.Decrypt(password)
+	if err != nil {
+		return nil, nil, helpers.NewError(helpers.KindInput, "decrypting keystore", err)
+	}
+	return privateKey, ks, nil
+}
+
+// returns the private key given in the builder inputs or, with -keystore, held by the keystore
+func privateKeyInput(pp helpers.TestingParams, raw json.RawMessage) (*big.Int, *keys.Keystore, error) {
+	if len(pp.Keystore) == 0 {
+		privateKey, err := parseValue("privateKey", raw)
+		if err != nil {
+			return nil, nil, helpers.NewError(helpers.KindInput, "parsing inputs", err)
+		}
+		return privateKey, nil, nil
+	}
+
+	if raw != nil {
+		return nil, nil, helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("privateKey can not be given with a keystore"))
+	}
+	return unlockKeystore(pp.Keystore, pp.PasswordFile)
+}
+
+// fills the private key input of the named circuit (see Circuit.PrivateKey) with the key of the keystore
+func (inputs *Inputs) setPrivateKey(pp helpers.TestingParams, name string) error {
+	circuit, ok := Circuits[name]
+	if !ok {
+		return helpers.NewError(helpers.KindInput, "loading circuit", fmt.Errorf("unknown circuit %s", name))
+	}
+	if len(circuit.PrivateKey) == 0 {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", fmt.Errorf("%s takes no private key, a keystore can not be used", name))
+	}
+	if inputs.Fields == nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("a keystore can only be used with named inputs"))
+	}
+	if _, ok := inputs.Fields[circuit.PrivateKey]; ok {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", fmt.Errorf("%s can not be given with a keystore", circuit.PrivateKey))
+	}
+
+	privateKey, _, err := unlockKeystore(pp.Keystore, pp.PasswordFile)
+	if err != nil {
+		return err
+	}
+	inputs.Fields[circuit.PrivateKey] = json.RawMessage(strconv.Quote(privateKey.String()))
+	return nil
+}
@@ -3,44 +3,43 @@ package hardhat
 import (
 	"encoding/json"
 
-	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/builder"
 	"github.com/ava-labs/EncryptedERC/pkg/helpers"
-	"github.com/ava-labs/EncryptedERC/pkg/utils"
-	"github.com/consensys/gnark/backend/groth16"
-	"github.com/consensys/gnark/frontend"
 )
 
-func Mint(pp helpers.TestingParams) {
-	inputString := pp.Input
-	var inputs Inputs
-	err := json.Unmarshal([]byte(inputString), &inputs)
-	if err != nil {
-		panic(err)
-	}
-
-	f := func() frontend.Circuit { return &circuits.MintCircuit{} }
+func Mint(pp helpers.TestingParams) error {
+	return prove(pp, "MINT")
+}
 
-	ccs, pk, vk, err := helpers.LoadCircuit(pp, f)
-	if err != nil {
-		panic(err)
+/*
+BuildMint proves a mint of the amount to the receiver, the ciphertexts and the nullifier are computed with fresh randomness
+
+	{ "receiverPublicKey": ["x", "y"], "auditorPublicKey": ["x", "y"], "amount": "...", "chainId": "43113" }
+*/
+func BuildMint(pp helpers.TestingParams) error {
+	var in struct {
+		ReceiverPublicKey babyjub.Point   `json:"receiverPublicKey"`
+		AuditorPublicKey  babyjub.Point   `json:"auditorPublicKey"`
+		Amount            json.RawMessage `json:"amount"`
+		ChainID           json.RawMessage `json:"chainId"`
+	}
+	if err := json.Unmarshal([]byte(pp.Input), &in); err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
 	}
 
-	witness, err := utils.GenerateWitness(inputs.PubIns, inputs.PrivIns)
+	amount, err := parseValue("amount", in.Amount)
 	if err != nil {
-		panic(err)
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
 	}
-
-	proof, err := groth16.Prove(ccs, pk, witness)
+	chainID, err := parseValue("chainId", in.ChainID)
 	if err != nil {
-		panic(err)
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
 	}
 
-	a, b, c := utils.SetProof(proof)
-	utils.WriteProof(pp.Output, &a, &b, &c)
-
-	if pp.Extract {
-		helpers.SaveCS(ccs, "MINT")
-		helpers.SavePK(pk, "MINT")
-		helpers.SaveVK(vk, "MINT")
+	assignment, _, err := builder.Mint(&in.ReceiverPublicKey, &in.AuditorPublicKey, amount, chainID, nil)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "building mint", err)
 	}
+	return proveAssignment(pp, "MINT", assignment, nil)
 }
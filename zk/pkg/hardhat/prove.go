@@ -0,0 +1,108 @@
+package hardhat
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/snarkjs"
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+)
+
+// parses the inputs, proves the named circuit and writes the proof with its public signals to the output file,
+// extracting the circuit artifacts as <dir>/<name>.* and the snarkjs proof and public signals if requested,
+// a dry run only reports the checks of the circuit for the inputs
+func prove(pp helpers.TestingParams, name string) error {
+	var inputs Inputs
+	err := json.Unmarshal([]byte(pp.Input), &inputs)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+
+	if len(pp.Keystore) != 0 {
+		if err := inputs.setPrivateKey(pp, name); err != nil {
+			return err
+		}
+	}
+
+	if pp.DryRun {
+		return dryRun(pp, name, inputs)
+	}
+
+	circuit, ok := Circuits[name]
+	if !ok {
+		return helpers.NewError(helpers.KindInput, "loading circuit", fmt.Errorf("unknown circuit %s", name))
+	}
+
+	witness, err := inputs.Witness(circuit.New())
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "generating witness", err)
+	}
+
+	return proveWitness(pp, name, witness, nil)
+}
+
+// proves the named circuit for the witness and writes the proof like prove with the extra fields,
+// the builders use it to prove the assignments they assemble
+func proveWitness(pp helpers.TestingParams, name string, witness witness.Witness, extra map[string]interface{}) error {
+	circuit, ok := Circuits[name]
+	if !ok {
+		return helpers.NewError(helpers.KindInput, "loading circuit", fmt.Errorf("unknown circuit %s", name))
+	}
+
+	ccs, pk, vk, err := helpers.LoadCircuit(pp, name, circuit.New)
+	if err != nil {
+		return err
+	}
+
+	publicSignals, err := circuit.PublicSignals(witness)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "extracting public signals", err)
+	}
+
+	proof, err := groth16.Prove(ccs, pk, witness)
+	if err != nil {
+		return helpers.NewError(helpers.KindConstraint, "proving", err)
+	}
+
+	a, b, c, err := utils.SetProof(proof)
+	if err != nil {
+		return helpers.NewError(helpers.KindUnknown, "serializing proof", err)
+	}
+
+	err = utils.WriteProofWith(pp.Output, &a, &b, &c, publicSignals, extra)
+	if err != nil {
+		return helpers.NewError(helpers.KindIO, "writing proof", err)
+	}
+
+	if pp.Snarkjs {
+		proofFile, publicFile := SnarkjsPaths(pp.Output)
+		err = snarkjs.WriteProof(proof, publicSignals, proofFile, publicFile)
+		if err != nil {
+			return helpers.NewError(helpers.KindIO, "writing snarkjs proof", err)
+		}
+	}
+
+	if pp.Extract {
+		return helpers.SaveArtifacts(ccs, pk, vk, pp.Dir, name)
+	}
+	return nil
+}
+
+// proves the assignment assembled by a builder, a dry run only reports its checks
+func proveAssignment(pp helpers.TestingParams, name string, assignment circuits.CheckedCircuit, extra map[string]interface{}) error {
+	if pp.DryRun {
+		return writeReport(pp, name, RunChecks(name, assignment))
+	}
+
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "generating witness", err)
+	}
+	return proveWitness(pp, name, witness, extra)
+}
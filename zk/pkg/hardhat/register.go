@@ -2,46 +2,57 @@ package hardhat
 
 import (
 	"encoding/json"
-	"fmt"
+	"strconv"
 
-	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/builder"
 	"github.com/ava-labs/EncryptedERC/pkg/helpers"
-	"github.com/ava-labs/EncryptedERC/pkg/utils"
-	"github.com/consensys/gnark/backend/groth16"
-	"github.com/consensys/gnark/frontend"
 )
 
-func Register(pp helpers.TestingParams) {
-	// Parse the JSON input string directly (not as a file path)
-	var inputs Inputs
-	err := json.Unmarshal([]byte(pp.Input), &inputs)
-	if err != nil {
-		panic(fmt.Sprintf("Error parsing JSON: %v", err))
-	}
+func Register(pp helpers.TestingParams) error {
+	return prove(pp, "REGISTER")
+}
 
-	f := func() frontend.Circuit { return &circuits.RegistrationCircuit{} }
+/*
+BuildRegister proves a registration from the private key alone, the public key and the registration hash are derived
 
-	ccs, pk, vk, err := helpers.LoadCircuit(pp, f)
-	if err != nil {
-		panic(err)
+	{ "privateKey": "...", "address": "0x...", "chainId": "43113" }
+
+With a keystore the private key is read from it, and the address and chain id default to the ones it is linked to.
+*/
+func BuildRegister(pp helpers.TestingParams) error {
+	var in struct {
+		PrivateKey json.RawMessage `json:"privateKey"`
+		Address    json.RawMessage `json:"address"`
+		ChainID    json.RawMessage `json:"chainId"`
+	}
+	if err := json.Unmarshal([]byte(pp.Input), &in); err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
 	}
 
-	witness, err := utils.GenerateWitness(inputs.PubIns, inputs.PrivIns)
+	privateKey, ks, err := privateKeyInput(pp, in.PrivateKey)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	// the keystore links the key to the address and chain id it registers
+	if ks != nil && in.Address == nil && len(ks.Address) != 0 {
+		in.Address = json.RawMessage(strconv.Quote(ks.Address))
+	}
+	if ks != nil && in.ChainID == nil && len(ks.ChainID) != 0 {
+		in.ChainID = json.RawMessage(strconv.Quote(ks.ChainID))
 	}
 
-	proof, err := groth16.Prove(ccs, pk, witness)
+	address, err := parseValue("address", in.Address)
 	if err != nil {
-		panic(err)
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	chainID, err := parseValue("chainId", in.ChainID)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
 	}
 
-	a, b, c := utils.SetProof(proof)
-	utils.WriteProof(pp.Output, &a, &b, &c)
-
-	if pp.Extract {
-		helpers.SaveCS(ccs, "REGISTER")
-		helpers.SavePK(pk, "REGISTER")
-		helpers.SaveVK(vk, "REGISTER")
+	assignment, err := builder.Registration(privateKey, address, chainID)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "building registration", err)
 	}
+	return proveAssignment(pp, "REGISTER", assignment, nil)
 }
@@ -0,0 +1,375 @@
+package hardhat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ava-labs/EncryptedERC/pkg/audit"
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/builder"
+	"github.com/ava-labs/EncryptedERC/pkg/events"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+)
+
+const rotationCircuit = "AUDITOR_ROTATION"
+
+// Handover is the bundle the outgoing auditor hands to the new auditor, every auditor PCT of the history is
+// re-encrypted for the new auditor with a proof that both PCTs hold the same value. The transactions are
+// read by audit.ParseJSON so the new auditor decrypts the bundle like any export.
+type Handover struct {
+	Circuit             string          `json:"circuit"`
+	OldAuditorPublicKey babyjub.Point   `json:"oldAuditorPublicKey"`
+	NewAuditorPublicKey babyjub.Point   `json:"newAuditorPublicKey"`
+	Transactions        []HandoverEntry `json:"transactions"`
+}
+
+// HandoverEntry is the re-encrypted auditor PCT of a transaction, a PCT that could not be re-encrypted
+// (e.g. emitted for an earlier auditor) is listed with the error instead of a proof. The error is the outgoing
+// auditor's word, VerifyHandover only exempts such a PCT if the logs dump shows it was emitted for an earlier auditor.
+type HandoverEntry struct {
+	TxHash   string `json:"txHash"`
+	Sender   string `json:"sender"`
+	Receiver string `json:"receiver"`
+	// uint256[7] PCTs, emitted for the old auditor and re-encrypted for the new one
+	PreviousAuditorPCT []string `json:"previousAuditorPCT,omitempty"`
+	AuditorPCT         []string `json:"auditorPCT,omitempty"`
+	Proof              []string `json:"proof,omitempty"`
+	PublicSignals      []string `json:"publicSignals,omitempty"`
+	Error              string   `json:"error,omitempty"`
+}
+
+func AuditorRotation(pp helpers.TestingParams) error {
+	return prove(pp, rotationCircuit)
+}
+
+/*
+RotateAuditor proves the re-encryption of the auditor PCTs of the exported transactions, or of the events of an
+eth_getLogs dump, for the new auditor and writes the handover bundle, the old auditor's private key is read from
+the keystore or the input
+
+	{ "privateKey": "...", "newAuditorPublicKey": ["x", "y"] }
+*/
+func RotateAuditor(pp helpers.TestingParams, transactionsPath, logsPath string) error {
+	if (len(transactionsPath) == 0) == (len(logsPath) == 0) {
+		return helpers.NewError(helpers.KindInput, "rotating auditor", errTransactionsSource)
+	}
+	if len(pp.Output) == 0 {
+		return helpers.NewError(helpers.KindInput, "rotating auditor", errors.New("output path is required"))
+	}
+
+	var in struct {
+		PrivateKey          json.RawMessage `json:"privateKey"`
+		NewAuditorPublicKey *babyjub.Point  `json:"newAuditorPublicKey"`
+	}
+	if err := json.Unmarshal([]byte(pp.Input), &in); err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	if in.NewAuditorPublicKey == nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("missing newAuditorPublicKey"))
+	}
+	privateKey, _, err := privateKeyInput(pp, in.PrivateKey)
+	if err != nil {
+		return err
+	}
+	oldPublicKey, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	if oldPublicKey.Equal(in.NewAuditorPublicKey) {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("the new auditor key is the old one"))
+	}
+
+	txs, err := readTransactions(transactionsPath, logsPath)
+	if err != nil {
+		return err
+	}
+
+	circuit := Circuits[rotationCircuit]
+	ccs, pk, vk, err := helpers.LoadCircuit(pp, rotationCircuit, circuit.New)
+	if err != nil {
+		return err
+	}
+
+	handover := Handover{
+		Circuit:             rotationCircuit,
+		OldAuditorPublicKey: oldPublicKey,
+		NewAuditorPublicKey: *in.NewAuditorPublicKey,
+		Transactions:        make([]HandoverEntry, 0, len(txs)),
+	}
+	proven := 0
+	for _, tx := range txs {
+		entry := HandoverEntry{TxHash: tx.TxHash, Sender: tx.Sender, Receiver: tx.Receiver, Error: tx.Error}
+		if tx.PCT != nil {
+			entry.PreviousAuditorPCT = decimalStrings(tx.PCT.Values())
+			if err := proveRotation(ccs, pk, privateKey, tx.PCT, in.NewAuditorPublicKey, &entry); err != nil {
+				if helpers.KindOf(err) != helpers.KindInput {
+					return err
+				}
+				entry.Error = err.Error()
+			} else {
+				proven++
+			}
+		}
+		handover.Transactions = append(handover.Transactions, entry)
+	}
+
+	out, err := json.MarshalIndent(handover, "", "  ")
+	if err != nil {
+		return helpers.NewError(helpers.KindUnknown, "writing handover", err)
+	}
+	if err := os.WriteFile(pp.Output, out, 0644); err != nil {
+		return helpers.NewError(helpers.KindIO, "writing handover", err)
+	}
+
+	fmt.Printf("%d transactions, %d re-encrypted, %d failed\n", len(txs), proven, len(txs)-proven)
+
+	if pp.Extract {
+		return helpers.SaveArtifacts(ccs, pk, vk, pp.Dir, rotationCircuit)
+	}
+	return nil
+}
+
+// re-encrypts the PCT for the new auditor and proves it, a PCT the old key does not decrypt is an input error
+func proveRotation(ccs constraint.ConstraintSystem, pk groth16.ProvingKey, privateKey *big.Int, pct *poseidon.PCT, newAuditor *babyjub.Point, entry *HandoverEntry) error {
+	assignment, publicSignals, newPCT, err := builder.AuditorRotation(privateKey, pct, newAuditor, nil)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "building rotation", err)
+	}
+
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "generating witness", err)
+	}
+	proof, err := groth16.Prove(ccs, pk, witness)
+	if err != nil {
+		return helpers.NewError(helpers.KindConstraint, "proving", err)
+	}
+	a, b, c, err := utils.SetProof(proof)
+	if err != nil {
+		return helpers.NewError(helpers.KindUnknown, "serializing proof", err)
+	}
+
+	entry.AuditorPCT = decimalStrings(newPCT.Values())
+	entry.Proof = utils.FormatProof(&a, &b, &c)
+	entry.PublicSignals = publicSignals
+	return nil
+}
+
+// HandoverResult is the outcome of verifying a handover bundle
+type HandoverResult struct {
+	Valid bool `json:"valid"`
+	// proofs that verify for the recorded PCTs and auditor keys
+	Verified int `json:"verified"`
+	Invalid  int `json:"invalid"`
+	// PCTs the AuditorChanged and Register events of the logs dump show were emitted for an earlier auditor
+	EarlierAuditor int `json:"earlierAuditor"`
+	// hashes of the transactions whose auditor PCT is not re-encrypted with a valid proof, nor emitted for an
+	// earlier auditor
+	Missing []string            `json:"missing,omitempty"`
+	Errors  []HandoverViolation `json:"errors,omitempty"`
+}
+
+type HandoverViolation struct {
+	TxHash string `json:"txHash"`
+	Error  string `json:"error"`
+}
+
+/*
+VerifyHandover verifies every proof of the handover bundle with the verifying key, against public signals rebuilt
+from the auditor keys and the PCTs of the bundle. If the transactions export or the logs dump is given, every
+previous PCT of the bundle must be one of their auditor PCTs and every one of them must be re-encrypted with a
+valid proof, so the history does not rest on the outgoing auditor's word. A PCT is only exempt if the AuditorChanged
+and Register events of the logs dump show it was emitted for an earlier auditor with another key, an entry without
+a proof is missing otherwise.
+*/
+func VerifyHandover(pp helpers.TestingParams, bundlePath, transactionsPath, logsPath string) (bool, error) {
+	if len(bundlePath) == 0 {
+		return false, helpers.NewError(helpers.KindInput, "verifying handover", errors.New("the handover bundle is required"))
+	}
+	if len(transactionsPath) != 0 && len(logsPath) != 0 {
+		return false, helpers.NewError(helpers.KindInput, "verifying handover", errors.New("the transactions export and the logs dump can not be combined"))
+	}
+	if len(pp.VkPath) == 0 {
+		return false, helpers.NewError(helpers.KindInput, "loading verifying key", errors.New("verifying key path is required"))
+	}
+
+	raw, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return false, helpers.NewError(helpers.KindIO, "reading handover", err)
+	}
+	var handover Handover
+	if err := json.Unmarshal(raw, &handover); err != nil {
+		return false, helpers.NewError(helpers.KindInput, "reading handover", fmt.Errorf("%s: %w", bundlePath, err))
+	}
+	if handover.Circuit != rotationCircuit {
+		return false, helpers.NewError(helpers.KindInput, "reading handover", fmt.Errorf("%s: not a handover bundle", bundlePath))
+	}
+
+	vk, err := helpers.ReadVK(pp.VkPath)
+	if err != nil {
+		return false, err
+	}
+
+	history, err := rotationHistory(transactionsPath, logsPath, &handover.OldAuditorPublicKey)
+	if err != nil {
+		return false, err
+	}
+
+	var result HandoverResult
+	invalid := func(entry HandoverEntry, err string) {
+		result.Invalid++
+		result.Errors = append(result.Errors, HandoverViolation{TxHash: entry.TxHash, Error: err})
+	}
+	for _, entry := range handover.Transactions {
+		key := strings.Join(entry.PreviousAuditorPCT, ",")
+		_, inHistory := history[key]
+
+		if entry.Proof == nil {
+			// left in the history, it is missing unless it was emitted for an earlier auditor
+			if history == nil {
+				result.Missing = append(result.Missing, entry.TxHash)
+			}
+			continue
+		}
+		if history != nil && !inHistory {
+			invalid(entry, "the previous auditor PCT is not one of the transactions")
+			continue
+		}
+
+		signals, err := rotationSignals(&handover, &entry)
+		if err != nil {
+			invalid(entry, err.Error())
+			continue
+		}
+		if entry.PublicSignals != nil && !slices.Equal(signals, entry.PublicSignals) {
+			invalid(entry, "the public signals do not match the auditor keys and PCTs")
+			continue
+		}
+
+		valid, err := VerifyProof(vk, entry.Proof, signals)
+		if err != nil {
+			invalid(entry, err.Error())
+			continue
+		}
+		if !valid {
+			invalid(entry, "invalid proof")
+			continue
+		}
+		result.Verified++
+		delete(history, key)
+	}
+	for _, pct := range history {
+		if pct.earlierAuditor {
+			result.EarlierAuditor++
+		} else {
+			result.Missing = append(result.Missing, pct.txHash)
+		}
+	}
+	slices.Sort(result.Missing)
+	result.Valid = result.Invalid == 0 && len(result.Missing) == 0
+
+	fmt.Printf("%d verified, %d invalid, %d emitted for an earlier auditor, %d missing\n",
+		result.Verified, result.Invalid, result.EarlierAuditor, len(result.Missing))
+
+	if len(pp.Output) != 0 {
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return result.Valid, helpers.NewError(helpers.KindUnknown, "writing result", err)
+		}
+		if err := os.WriteFile(pp.Output, out, 0644); err != nil {
+			return result.Valid, helpers.NewError(helpers.KindIO, "writing result", err)
+		}
+	}
+	return result.Valid, nil
+}
+
+// auditor PCT of the history a handover is checked against
+type rotationPCT struct {
+	txHash string
+	// emitted for an auditor whose registered key is not the outgoing one
+	earlierAuditor bool
+}
+
+/*
+auditor PCTs of the transactions export or the logs dump keyed by their decimal values, nil if neither is given.
+Only the logs dump tells which auditor a PCT was emitted for: the auditor the AuditorChanged events set before it,
+which must be the auditor of the event, and the key it registered. A PCT whose auditor or key the dump does not
+tell is the outgoing auditor's, like every PCT of an export.
+*/
+func rotationHistory(transactionsPath, logsPath string, outgoing *babyjub.Point) (map[string]rotationPCT, error) {
+	if len(transactionsPath) == 0 && len(logsPath) == 0 {
+		return nil, nil
+	}
+
+	var txs []audit.Transaction
+	var auditors []events.Auditor
+	if len(logsPath) != 0 {
+		decoded, err := readEvents(logsPath)
+		if err != nil {
+			return nil, err
+		}
+		txs = events.Transactions(decoded)
+		auditors = events.Auditors(decoded)
+	} else {
+		var err error
+		if txs, err = readTransactions(transactionsPath, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	history := make(map[string]rotationPCT, len(txs))
+	for i, tx := range txs {
+		if tx.PCT == nil {
+			continue
+		}
+		pct := rotationPCT{txHash: tx.TxHash}
+		if auditors != nil {
+			key := auditors[i].PublicKey
+			pct.earlierAuditor = key != nil && !key.Equal(outgoing)
+		}
+		history[strings.Join(decimalStrings(tx.PCT.Values()), ",")] = pct
+	}
+	return history, nil
+}
+
+// public signals of the rotation proof, in the order of the circuit fields: old auditor key, previous PCT,
+// new auditor key, new PCT
+func rotationSignals(handover *Handover, entry *HandoverEntry) ([]string, error) {
+	if len(entry.PreviousAuditorPCT) != 7 || len(entry.AuditorPCT) != 7 {
+		return nil, errors.New("the auditor PCTs must have 7 elements")
+	}
+
+	signals := make([]string, 0, Circuits[rotationCircuit].NbPublicSignals)
+	signals = append(signals, handover.OldAuditorPublicKey.X.String(), handover.OldAuditorPublicKey.Y.String())
+	signals = append(signals, entry.PreviousAuditorPCT...)
+	signals = append(signals, handover.NewAuditorPublicKey.X.String(), handover.NewAuditorPublicKey.Y.String())
+	signals = append(signals, entry.AuditorPCT...)
+
+	// the signals must be canonical decimal field elements to compare with the recorded ones
+	for i, s := range signals {
+		v, ok := new(big.Int).SetString(s, 10)
+		if !ok || v.Sign() < 0 || v.Cmp(ecc.BN254.ScalarField()) >= 0 || v.String() != s {
+			return nil, fmt.Errorf("public signal %d %q is not a decimal field element", i, s)
+		}
+	}
+	return signals, nil
+}
+
+func decimalStrings(values []*big.Int) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = v.String()
+	}
+	return out
+}
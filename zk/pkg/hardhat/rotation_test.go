@@ -0,0 +1,243 @@
+package hardhat
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/builder"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/poseidon"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+)
+
+var (
+	oldAuditorKey = big.NewInt(1234)
+	newAuditorKey = big.NewInt(5678)
+)
+
+func testPublicKey(t *testing.T, privateKey *big.Int) babyjub.Point {
+	t.Helper()
+	pk, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pk
+}
+
+// auditor PCTs of the amounts encrypted for the old auditor, with their transaction hashes
+func testHistory(t *testing.T, amounts ...int64) ([]string, []*poseidon.PCT) {
+	t.Helper()
+	oldPublicKey := testPublicKey(t, oldAuditorKey)
+
+	hashes := make([]string, len(amounts))
+	pcts := make([]*poseidon.PCT, len(amounts))
+	for i, amount := range amounts {
+		pct, _, err := poseidon.NewPCT(&oldPublicKey, []*big.Int{big.NewInt(amount)}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes[i] = fmt.Sprintf("0x%064x", i+1)
+		pcts[i] = pct
+	}
+	return hashes, pcts
+}
+
+// writes the transactions as a JSON export in the uint256[7] layout
+func writeExport(t *testing.T, path string, hashes []string, pcts []*poseidon.PCT) {
+	t.Helper()
+	rows := make([]map[string]interface{}, len(pcts))
+	for i, pct := range pcts {
+		rows[i] = map[string]interface{}{"txHash": hashes[i], "sender": "0x01", "receiver": "0x02", "auditorPCT": decimalStrings(pct.Values())}
+	}
+	writeJSONFile(t, path, rows)
+}
+
+func writeJSONFile(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// proves the re-encryption of every PCT for the new auditor
+func testHandover(t *testing.T, ccs constraint.ConstraintSystem, pk groth16.ProvingKey, hashes []string, pcts []*poseidon.PCT) *Handover {
+	t.Helper()
+	newPublicKey := testPublicKey(t, newAuditorKey)
+
+	handover := &Handover{
+		Circuit:             rotationCircuit,
+		OldAuditorPublicKey: testPublicKey(t, oldAuditorKey),
+		NewAuditorPublicKey: newPublicKey,
+	}
+	for i, pct := range pcts {
+		entry := HandoverEntry{TxHash: hashes[i], Sender: "0x01", Receiver: "0x02", PreviousAuditorPCT: decimalStrings(pct.Values())}
+		if err := proveRotation(ccs, pk, oldAuditorKey, pct, &newPublicKey, &entry); err != nil {
+			t.Fatal(err)
+		}
+		handover.Transactions = append(handover.Transactions, entry)
+	}
+	return handover
+}
+
+func TestVerifyHandover(t *testing.T) {
+	ccs, pk, vk, err := helpers.LoadCircuit(helpers.TestingParams{IsNew: true}, rotationCircuit, Circuits[rotationCircuit].New)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	vkBase := filepath.Join(dir, rotationCircuit)
+	if err := helpers.SaveRawVK(vk, vkBase); err != nil {
+		t.Fatal(err)
+	}
+	pp := helpers.TestingParams{VkPath: vkBase + ".vk", Output: filepath.Join(dir, "result.json")}
+
+	hashes, pcts := testHistory(t, 10, 20, 30)
+	valid := testHandover(t, ccs, pk, hashes, pcts)
+
+	export := filepath.Join(dir, "transactions.json")
+	writeExport(t, export, hashes, pcts)
+	// the last transaction is left out of the export
+	partialExport := filepath.Join(dir, "partial.json")
+	writeExport(t, partialExport, hashes[:2], pcts[:2])
+	// an extra transaction the bundle does not re-encrypt
+	extraHashes, extraPCTs := testHistory(t, 40)
+	extraHashes[0] = "0xextra"
+	extendedExport := filepath.Join(dir, "extended.json")
+	writeExport(t, extendedExport, append(slices.Clone(hashes), extraHashes...), append(slices.Clone(pcts), extraPCTs...))
+
+	newPublicKey := testPublicKey(t, newAuditorKey)
+	otherPCT, _, err := poseidon.NewPCT(&newPublicKey, []*big.Int{big.NewInt(10)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(h *Handover)
+		history string
+		valid   bool
+		invalid int
+		missing []string
+	}{
+		{name: "valid without history", modify: func(h *Handover) {}, valid: true},
+		{name: "valid", modify: func(h *Handover) {}, history: export, valid: true},
+		{name: "new PCT swapped", modify: func(h *Handover) {
+			h.Transactions[0].AuditorPCT = decimalStrings(otherPCT.Values())
+		}, history: export, invalid: 1, missing: hashes[:1]},
+		{name: "new PCT and public signals swapped", modify: func(h *Handover) {
+			h.Transactions[0].AuditorPCT = decimalStrings(otherPCT.Values())
+			h.Transactions[0].PublicSignals = nil
+		}, history: export, invalid: 1, missing: hashes[:1]},
+		{name: "new auditor key swapped", modify: func(h *Handover) {
+			h.NewAuditorPublicKey = testPublicKey(t, big.NewInt(9999))
+		}, history: export, invalid: 3, missing: hashes},
+		{name: "PCT missing from the history", modify: func(h *Handover) {}, history: partialExport, invalid: 1},
+		{name: "PCT of the history not re-encrypted", modify: func(h *Handover) {}, history: extendedExport, missing: []string{"0xextra"}},
+		{name: "entry without a proof", modify: func(h *Handover) {
+			h.Transactions[1].AuditorPCT, h.Transactions[1].Proof, h.Transactions[1].PublicSignals = nil, nil, nil
+			h.Transactions[1].Error = "not encrypted for the old auditor key"
+		}, history: export, missing: []string{hashes[1]}},
+		{name: "entry without a proof and without history", modify: func(h *Handover) {
+			h.Transactions[1].AuditorPCT, h.Transactions[1].Proof, h.Transactions[1].PublicSignals = nil, nil, nil
+		}, missing: []string{hashes[1]}},
+	}
+
+	for _, tt := range tests {
+		// deep copy of the valid bundle
+		var handover Handover
+		raw, err := json.Marshal(valid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(raw, &handover); err != nil {
+			t.Fatal(err)
+		}
+		tt.modify(&handover)
+
+		bundle := filepath.Join(dir, "handover.json")
+		writeJSONFile(t, bundle, &handover)
+
+		ok, err := VerifyHandover(pp, bundle, tt.history, "")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		var result HandoverResult
+		raw, err = os.ReadFile(pp.Output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(raw, &result); err != nil {
+			t.Fatal(err)
+		}
+
+		if ok != tt.valid || result.Valid != tt.valid {
+			t.Errorf("%s: expected valid %v, got %v (%+v)", tt.name, tt.valid, ok, result)
+		}
+		if result.Invalid != tt.invalid {
+			t.Errorf("%s: expected %d invalid, got %d (%+v)", tt.name, tt.invalid, result.Invalid, result.Errors)
+		}
+		if !slices.Equal(result.Missing, tt.missing) {
+			t.Errorf("%s: expected missing %v, got %v", tt.name, tt.missing, result.Missing)
+		}
+	}
+}
+
+// the public signals VerifyHandover rebuilds must be the ones of the witness the builder assigns
+func TestRotationSignals(t *testing.T) {
+	_, pcts := testHistory(t, 10)
+	newPublicKey := testPublicKey(t, newAuditorKey)
+
+	_, expected, newPCT, err := builder.AuditorRotation(oldAuditorKey, pcts[0], &newPublicKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handover := Handover{OldAuditorPublicKey: testPublicKey(t, oldAuditorKey), NewAuditorPublicKey: newPublicKey}
+	entry := HandoverEntry{PreviousAuditorPCT: decimalStrings(pcts[0].Values()), AuditorPCT: decimalStrings(newPCT.Values())}
+	signals, err := rotationSignals(&handover, &entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(signals, expected) {
+		t.Fatalf("expected the public signals of the witness\n%v\ngot\n%v", expected, signals)
+	}
+	if len(signals) != Circuits[rotationCircuit].NbPublicSignals {
+		t.Fatalf("expected %d public signals, got %d", Circuits[rotationCircuit].NbPublicSignals, len(signals))
+	}
+
+	// a PCT of the wrong layout or a signal that is not a canonical field element
+	entry.AuditorPCT = entry.AuditorPCT[:4]
+	if _, err := rotationSignals(&handover, &entry); err == nil {
+		t.Fatal("expected an error for a 4 element PCT")
+	}
+	entry.AuditorPCT = decimalStrings(newPCT.Values())
+	entry.AuditorPCT[0] = "0" + entry.AuditorPCT[0]
+	if _, err := rotationSignals(&handover, &entry); err == nil {
+		t.Fatal("expected an error for a non-canonical signal")
+	}
+}
+
+func TestAuditorRotationRejectsForeignPCT(t *testing.T) {
+	newPublicKey := testPublicKey(t, newAuditorKey)
+	// a PCT emitted for the new auditor does not decrypt with the old key
+	pct, _, err := poseidon.NewPCT(&newPublicKey, []*big.Int{big.NewInt(10)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := builder.AuditorRotation(oldAuditorKey, pct, &newPublicKey, nil); err == nil {
+		t.Fatal("expected an error for a PCT not encrypted for the old auditor")
+	}
+}
@@ -0,0 +1,88 @@
+package hardhat
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/builder"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+)
+
+func BalanceThreshold(pp helpers.TestingParams) error {
+	return prove(pp, "BALANCE_THRESHOLD")
+}
+
+/*
+BuildBalanceThreshold proves the sender's current balance is at least the threshold, and at most the upper bound
+if one is given, for the challenge chosen by the verifier, which is required. The balance is decrypted with the table if it is not
+given, the private key is read from the keystore if there is one.
+
+	{
+		"privateKey": "...", "balanceEGCT": { "c1": ["x", "y"], "c2": ["x", "y"] }, "balance": "...",
+		"threshold": "...", "upperBound": "...", "challenge": "..."
+	}
+*/
+func BuildBalanceThreshold(pp helpers.TestingParams, tp TableParams) error {
+	var in struct {
+		PrivateKey  json.RawMessage    `json:"privateKey"`
+		BalanceEGCT babyjub.Ciphertext `json:"balanceEGCT"`
+		Balance     json.RawMessage    `json:"balance"`
+		Threshold   json.RawMessage    `json:"threshold"`
+		UpperBound  json.RawMessage    `json:"upperBound"`
+		Challenge   json.RawMessage    `json:"challenge"`
+	}
+	if err := json.Unmarshal([]byte(pp.Input), &in); err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+
+	privateKey, _, err := privateKeyInput(pp, in.PrivateKey)
+	if err != nil {
+		return err
+	}
+	if err := babyjub.CheckPrivateKey(privateKey); err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	threshold, err := parseValue("threshold", in.Threshold)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+
+	challenge, err := parseValue("challenge", in.Challenge)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	if challenge.Sign() == 0 {
+		fmt.Fprintln(os.Stderr, "warning: the challenge is zero, the proof can be replayed to any verifier accepting a zero challenge")
+	}
+
+	// an optional upper bound is left for the builder to default
+	var upperBound *big.Int
+	if in.UpperBound != nil {
+		if upperBound, err = parseValue("upperBound", in.UpperBound); err != nil {
+			return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+		}
+	}
+
+	// an uninitialized balance is left for the builder to refuse
+	var balance *big.Int
+	if in.Balance != nil {
+		balance, err = parseValue("balance", in.Balance)
+		if err != nil {
+			return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+		}
+	} else if !in.BalanceEGCT.IsZero() {
+		balance, err = decryptBalance(tp, privateKey, &in.BalanceEGCT)
+		if err != nil {
+			return err
+		}
+	}
+
+	assignment, _, err := builder.BalanceThreshold(privateKey, &in.BalanceEGCT, balance, threshold, upperBound, challenge)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "building balance threshold", err)
+	}
+	return proveAssignment(pp, "BALANCE_THRESHOLD", assignment, nil)
+}
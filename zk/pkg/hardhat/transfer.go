@@ -2,45 +2,74 @@ package hardhat
 
 import (
 	"encoding/json"
+	"math/big"
 
-	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/ava-labs/EncryptedERC/pkg/builder"
 	"github.com/ava-labs/EncryptedERC/pkg/helpers"
-	"github.com/ava-labs/EncryptedERC/pkg/utils"
-	"github.com/consensys/gnark/backend/groth16"
-	"github.com/consensys/gnark/frontend"
 )
 
-func Transfer(pp helpers.TestingParams) {
-	inputString := pp.Input
-	var inputs Inputs
-	err := json.Unmarshal([]byte(inputString), &inputs)
-	if err != nil {
-		panic(err)
-	}
+func Transfer(pp helpers.TestingParams) error {
+	return prove(pp, "TRANSFER")
+}
 
-	f := func() frontend.Circuit { return &circuits.TransferCircuit{} }
+/*
+BuildTransfer proves a transfer of the amount from the sender's current balance and writes the sender's new
+balance PCT next to the proof as "balancePCT", the balance is decrypted with the table if it is not given.
+The private key is read from the keystore if there is one.
 
-	ccs, pk, vk, err := helpers.LoadCircuit(pp, f)
-	if err != nil {
-		panic(err)
+	{
+		"privateKey": "...", "balanceEGCT": { "c1": ["x", "y"], "c2": ["x", "y"] }, "balance": "...",
+		"receiverPublicKey": ["x", "y"], "auditorPublicKey": ["x", "y"], "amount": "..."
+	}
+*/
+func BuildTransfer(pp helpers.TestingParams, tp TableParams) error {
+	var in struct {
+		PrivateKey        json.RawMessage    `json:"privateKey"`
+		BalanceEGCT       babyjub.Ciphertext `json:"balanceEGCT"`
+		Balance           json.RawMessage    `json:"balance"`
+		ReceiverPublicKey babyjub.Point      `json:"receiverPublicKey"`
+		AuditorPublicKey  babyjub.Point      `json:"auditorPublicKey"`
+		Amount            json.RawMessage    `json:"amount"`
+	}
+	if err := json.Unmarshal([]byte(pp.Input), &in); err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
 	}
 
-	witness, err := utils.GenerateWitness(inputs.PubIns, inputs.PrivIns)
+	privateKey, _, err := privateKeyInput(pp, in.PrivateKey)
 	if err != nil {
-		panic(err)
+		return err
 	}
-
-	proof, err := groth16.Prove(ccs, pk, witness)
+	amount, err := parseValue("amount", in.Amount)
 	if err != nil {
-		panic(err)
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	if err := babyjub.CheckPrivateKey(privateKey); err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+
+	// an uninitialized balance is left for the builder to refuse
+	var balance *big.Int
+	if in.Balance != nil {
+		balance, err = parseValue("balance", in.Balance)
+		if err != nil {
+			return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+		}
+	} else if !in.BalanceEGCT.IsZero() {
+		balance, err = decryptBalance(tp, privateKey, &in.BalanceEGCT)
+		if err != nil {
+			return err
+		}
 	}
 
-	a, b, c := utils.SetProof(proof)
-	utils.WriteProof(pp.Output, &a, &b, &c)
+	assignment, _, balancePCT, err := builder.Transfer(privateKey, &in.BalanceEGCT, balance, &in.ReceiverPublicKey, &in.AuditorPublicKey, amount, nil)
+	if err != nil {
+		return helpers.NewError(helpers.KindInput, "building transfer", err)
+	}
 
-	if pp.Extract {
-		helpers.SaveCS(ccs, "TRANSFER")
-		helpers.SavePK(pk, "TRANSFER")
-		helpers.SaveVK(vk, "TRANSFER")
+	values := make([]string, 0, 7)
+	for _, v := range balancePCT.Values() {
+		values = append(values, v.String())
 	}
+	return proveAssignment(pp, "TRANSFER", assignment, map[string]interface{}{"balancePCT": values})
 }
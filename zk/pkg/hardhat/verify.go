@@ -0,0 +1,78 @@
+package hardhat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
+	"github.com/consensys/gnark/backend/groth16"
+)
+
+// VerifyInputs is the proof as written by utils.WriteProof together with its public signals
+type VerifyInputs struct {
+	Proof         []string `json:"proof"`
+	PublicSignals []string `json:"publicSignals"`
+}
+
+// VerifyProof checks the proof against the public signals with the given verifying key,
+// it returns an error only if the inputs are malformed
+func VerifyProof(vk groth16.VerifyingKey, proof []string, publicSignals []string) (bool, error) {
+	p, err := utils.ParseProof(proof)
+	if err != nil {
+		return false, err
+	}
+
+	if nb := vk.NbPublicWitness(); nb != len(publicSignals) {
+		return false, fmt.Errorf("verifying key expects %d public signals, got %d", nb, len(publicSignals))
+	}
+
+	publicWitness, err := utils.PublicWitness(publicSignals)
+	if err != nil {
+		return false, err
+	}
+
+	return groth16.Verify(p, vk, publicWitness) == nil, nil
+}
+
+func Verify(pp helpers.TestingParams) (bool, error) {
+	var inputs VerifyInputs
+	err := json.Unmarshal([]byte(pp.Input), &inputs)
+	if err != nil {
+		return false, helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+
+	if len(pp.VkPath) == 0 {
+		return false, helpers.NewError(helpers.KindInput, "loading verifying key", errors.New("verifying key path is required"))
+	}
+
+	vk, err := helpers.ReadVK(pp.VkPath)
+	if err != nil {
+		return false, err
+	}
+
+	valid, err := VerifyProof(vk, inputs.Proof, inputs.PublicSignals)
+	if err != nil {
+		return false, helpers.NewError(helpers.KindInput, "verifying proof", err)
+	}
+
+	if valid {
+		fmt.Println("valid")
+	} else {
+		fmt.Println("invalid")
+	}
+
+	if len(pp.Output) != 0 {
+		result, err := json.Marshal(map[string]interface{}{"valid": valid})
+		if err != nil {
+			return valid, helpers.NewError(helpers.KindUnknown, "writing result", err)
+		}
+		if err := os.WriteFile(pp.Output, result, 0644); err != nil {
+			return valid, helpers.NewError(helpers.KindIO, "writing result", err)
+		}
+	}
+
+	return valid, nil
+}
@@ -0,0 +1,68 @@
+package helpers
+
+import "errors"
+
+// ErrorKind classifies failures so that callers can tell bad input apart from broken artifacts
+type ErrorKind int
+
+const (
+	KindUnknown ErrorKind = iota
+	// malformed input JSON or witness values
+	KindInput
+	// missing or unreadable constraint system, proving key or verifying key
+	KindArtifact
+	// the witness does not satisfy the circuit constraints
+	KindConstraint
+	// reading or writing files other than the circuit artifacts
+	KindIO
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindInput:
+		return "input"
+	case KindArtifact:
+		return "artifact"
+	case KindConstraint:
+		return "constraint"
+	case KindIO:
+		return "io"
+	default:
+		return "unknown"
+	}
+}
+
+// Error wraps an error with its kind and the operation that failed
+type Error struct {
+	Kind ErrorKind
+	Op   string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Op == "" {
+		return e.Err.Error()
+	}
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError wraps err with the given kind, returns nil if err is nil
+func NewError(kind ErrorKind, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Op: op, Err: err}
+}
+
+// KindOf returns the kind of the first Error in the chain of err
+func KindOf(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindUnknown
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"io"
 	"os"
+	"path/filepath"
 
+	"github.com/ava-labs/EncryptedERC/pkg/snarkjs"
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/constraint"
@@ -19,15 +21,28 @@ type TestingParams struct {
 	Output  string
 	CsPath  string
 	PkPath  string
+	VkPath  string
+	Dir     string
 	IsNew   bool
 	Extract bool
+	// solve the circuit checks for the inputs without loading keys or proving
+	DryRun bool
+	// also write the proof as snarkjs proof.json and public.json next to Output
+	Snarkjs bool
+	// keystore holding the private key of the sender instead of the inputs, and the file holding its password
+	Keystore     string
+	PasswordFile string
+	// accept artifacts without a manifest, extracted before manifests were introduced
+	AllowMissingManifest bool
 }
 
 // function loads the contents of the circuit and the keys
 // if isNew, it compiles and generates the keys for the first time
-// otherwise, it reads the circuit and the keys from the given paths
+// otherwise, it reads the circuit and the keys from the given paths, or <dir>/<name>.* by default,
+// and checks they match each other, the circuit and its manifest
 func LoadCircuit(
 	params TestingParams,
+	name string,
 	f func() frontend.Circuit,
 ) (constraint.ConstraintSystem, groth16.ProvingKey, groth16.VerifyingKey, error) {
 	var err error
@@ -37,26 +52,52 @@ func LoadCircuit(
 
 	if params.IsNew {
 		if ccs, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, f()); err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, NewError(KindArtifact, "compiling circuit", err)
 		}
 
 		if pk, vk, err = groth16.Setup(ccs); err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, NewError(KindArtifact, "generating keys", err)
+		}
+		if err = utils.OrderVerifyingKey(f(), vk); err != nil {
+			return nil, nil, nil, NewError(KindArtifact, "generating keys", err)
 		}
 
 	} else {
-		if len(params.CsPath) == 0 || len(params.PkPath) == 0 {
-			return nil, nil, nil, errors.New("r1cs and pk paths are required for existing circuit")
+		if params.Extract && len(params.VkPath) == 0 {
+			return nil, nil, nil, NewError(KindInput, "loading circuit", errors.New("the verifying key path is required to extract existing artifacts"))
+		}
+
+		csPath, pkPath := params.CsPath, params.PkPath
+		if len(csPath) == 0 && len(params.Dir) != 0 {
+			csPath = filepath.Join(params.Dir, name+".r1cs")
+		}
+		if len(pkPath) == 0 && len(params.Dir) != 0 {
+			pkPath = filepath.Join(params.Dir, name+".pk")
+		}
+		if len(csPath) == 0 || len(pkPath) == 0 {
+			return nil, nil, nil, NewError(KindInput, "loading circuit", errors.New("r1cs and pk paths or an artifact directory are required for existing circuit"))
 		}
 
-		ccs, err = ReadCS(params.CsPath)
+		ccs, err = ReadCS(csPath)
 		if err != nil {
 			return nil, nil, nil, err
 		}
-		pk, err = ReadPK(params.PkPath)
+		pk, err = ReadPK(pkPath)
 		if err != nil {
 			return nil, nil, nil, err
 		}
+
+		if err = VerifyArtifacts(name, f(), ccs, pk, csPath, pkPath, params.AllowMissingManifest); err != nil {
+			return nil, nil, nil, err
+		}
+
+		// verifying key is optional, only needed to verify the generated proofs
+		if len(params.VkPath) != 0 {
+			vk, err = ReadVK(params.VkPath)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
 	}
 
 	return ccs, pk, vk, nil
@@ -65,98 +106,142 @@ func LoadCircuit(
 // reads the constraint system from the provided path
 func ReadCS(filename string) (constraint.ConstraintSystem, error) {
 	ccs := groth16.NewCS(ecc.BN254)
-	// Read the proving key
+
 	csFile, err := os.ReadFile(filename)
 	if err != nil {
-		return ccs, err
+		return ccs, NewError(KindArtifact, "reading constraint system", err)
 	}
 
-	pkCs := bytes.NewBuffer(csFile)
-	_, err = ccs.ReadFrom(pkCs)
+	_, err = ccs.ReadFrom(bytes.NewBuffer(csFile))
 	if err != nil {
-		panic(err)
+		return ccs, NewError(KindArtifact, "reading constraint system", fmt.Errorf("%s: %w", filename, err))
 	}
 
-	return ccs, err
+	return ccs, nil
 }
 
 // reads the proving key from the provided path
 func ReadPK(filename string) (groth16.ProvingKey, error) {
 	pk := groth16.NewProvingKey(ecc.BN254)
 
-	// Read the verifying key
 	pkFile, err := os.ReadFile(filename)
 	if err != nil {
-		fmt.Println("Error:", err)
-		return pk, err
+		return pk, NewError(KindArtifact, "reading proving key", err)
 	}
 
-	pkBuff := bytes.NewBuffer(pkFile)
-	_, err = pk.ReadFrom(pkBuff)
+	_, err = pk.ReadFrom(bytes.NewBuffer(pkFile))
 	if err != nil {
-		panic(err)
+		return pk, NewError(KindArtifact, "reading proving key", fmt.Errorf("%s: %w", filename, err))
 	}
 
-	return pk, err
+	return pk, nil
 }
 
-// saves proving key to the provided path
-func SavePK(pk groth16.ProvingKey, filename string) {
-	var bufPK bytes.Buffer
-	_, err := pk.WriteTo(&bufPK)
+// reads the verifying key from the provided path
+func ReadVK(filename string) (groth16.VerifyingKey, error) {
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+
+	vkFile, err := os.ReadFile(filename)
 	if err != nil {
-		panic(err)
+		return vk, NewError(KindArtifact, "reading verifying key", err)
 	}
 
-	file, err := os.Create(filename + ".pk")
+	_, err = vk.ReadFrom(bytes.NewBuffer(vkFile))
 	if err != nil {
-		fmt.Println("Error:", err)
-		return
+		return vk, NewError(KindArtifact, "reading verifying key", fmt.Errorf("%s: %w", filename, err))
 	}
-	defer file.Close()
 
-	_, err = io.Copy(file, &bufPK)
+	return vk, nil
+}
 
+// saves proving key to the provided path
+func SavePK(pk groth16.ProvingKey, filename string) error {
+	var bufPK bytes.Buffer
+	_, err := pk.WriteTo(&bufPK)
 	if err != nil {
-		fmt.Println("Error:", err)
-		return
+		return NewError(KindArtifact, "serializing proving key", err)
 	}
+
+	return NewError(KindIO, "saving proving key", os.WriteFile(filename+".pk", bufPK.Bytes(), 0644))
 }
 
 // saves constraint system to the provided path
-func SaveCS(cs constraint.ConstraintSystem, filename string) {
-	// Open the output file for writing
+func SaveCS(cs constraint.ConstraintSystem, filename string) error {
 	var bufCS bytes.Buffer
 	_, err := cs.WriteTo(&bufCS)
 	if err != nil {
-		panic(err)
+		return NewError(KindArtifact, "serializing constraint system", err)
 	}
 
-	// Open the output file for writing
-	file, err := os.Create(filename + ".r1cs")
+	return NewError(KindIO, "saving constraint system", os.WriteFile(filename+".r1cs", bufCS.Bytes(), 0644))
+}
+
+// saves verifying key to the provided path
+func SaveVK(vk groth16.VerifyingKey, filename string) error {
+	fileVK, err := os.Create(filename + ".sol")
 	if err != nil {
-		fmt.Println("Error:", err)
-		return
+		return NewError(KindIO, "saving solidity verifier", err)
 	}
-	defer file.Close()
-
-	// Write the buffer contents to the file
-	_, err = io.Copy(file, &bufCS)
+	defer fileVK.Close()
 
+	err = vk.ExportSolidity(fileVK)
 	if err != nil {
-		fmt.Println("Error:", err)
-		return
+		return NewError(KindIO, "saving solidity verifier", err)
 	}
+	return nil
 }
 
-// saves verifying key to the provided path
-func SaveVK(vk groth16.VerifyingKey, filename string) {
-	fileVK, err := os.Create(filename + ".sol")
+// saves the serialized verifying key to the provided path, readable with ReadVK
+func SaveRawVK(vk groth16.VerifyingKey, filename string) error {
+	var bufVK bytes.Buffer
+	_, err := vk.WriteTo(&bufVK)
 	if err != nil {
-		panic(err)
+		return NewError(KindArtifact, "serializing verifying key", err)
 	}
-	err = vk.ExportSolidity(fileVK)
+
+	return NewError(KindIO, "saving verifying key", os.WriteFile(filename+".vk", bufVK.Bytes(), 0644))
+}
+
+// saves the verifying key as snarkjs <name>_verification_key.json
+func SaveSnarkjsVK(vk groth16.VerifyingKey, filename string) error {
+	key, err := snarkjs.ExportVerifyingKey(vk)
 	if err != nil {
-		panic(err)
+		return NewError(KindArtifact, "exporting snarkjs verifying key", err)
 	}
+
+	return NewError(KindIO, "saving snarkjs verifying key", snarkjs.WriteJSON(filename+"_verification_key.json", key))
+}
+
+// saves the constraint system, proving key, solidity verifier and verifying keys (gnark and snarkjs) as <dir>/<name>.*
+// together with the manifest listing their hashes. The verifying key is required so the manifest lists every artifact.
+func SaveArtifacts(ccs constraint.ConstraintSystem, pk groth16.ProvingKey, vk groth16.VerifyingKey, dir, name string) error {
+	if vk == nil {
+		return NewError(KindInput, "saving artifacts", errors.New("the verifying key is required to extract the artifacts"))
+	}
+	if len(dir) == 0 {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return NewError(KindIO, "creating artifact directory", err)
+	}
+	base := filepath.Join(dir, name)
+
+	if err := SaveCS(ccs, base); err != nil {
+		return err
+	}
+	if err := SavePK(pk, base); err != nil {
+		return err
+	}
+	if err := SaveVK(vk, base); err != nil {
+		return err
+	}
+	if err := SaveRawVK(vk, base); err != nil {
+		return err
+	}
+	if err := SaveSnarkjsVK(vk, base); err != nil {
+		return err
+	}
+
+	files := []string{name + ".r1cs", name + ".pk", name + ".sol", name + ".vk", name + "_verification_key.json"}
+	return SaveManifest(ccs, dir, name, files)
 }
@@ -0,0 +1,213 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// Manifest describes the artifacts of a circuit, it is written next to them as <name>.manifest.json
+type Manifest struct {
+	Circuit        string `json:"circuit"`
+	GnarkVersion   string `json:"gnarkVersion"`
+	NbConstraints  int    `json:"nbConstraints"`
+	NbPublicInputs int    `json:"nbPublicInputs"`
+	// sha256 of every artifact keyed by file name
+	Files map[string]string `json:"files"`
+}
+
+func ManifestPath(dir, name string) string {
+	return filepath.Join(dir, name+".manifest.json")
+}
+
+// writes the manifest of the artifacts saved as <dir>/<name>.*
+func SaveManifest(ccs constraint.ConstraintSystem, dir, name string, files []string) error {
+	manifest := Manifest{
+		Circuit:        name,
+		GnarkVersion:   gnark.Version.String(),
+		NbConstraints:  ccs.GetNbConstraints(),
+		NbPublicInputs: ccs.GetNbPublicVariables() - 1,
+		Files:          make(map[string]string, len(files)),
+	}
+
+	for _, file := range files {
+		sum, err := sha256File(filepath.Join(dir, file))
+		if err != nil {
+			return NewError(KindIO, "hashing artifacts", err)
+		}
+		manifest.Files[file] = sum
+	}
+
+	out, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return NewError(KindUnknown, "saving manifest", err)
+	}
+	return NewError(KindIO, "saving manifest", os.WriteFile(ManifestPath(dir, name), out, 0644))
+}
+
+func ReadManifest(filename string) (*Manifest, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return &manifest, nil
+}
+
+// VerifyArtifacts refuses a constraint system that is not the one the circuit compiles to, and a proving key
+// that does not match it or is not the one listed next to it in the manifest. Artifacts without a manifest
+// (extracted before manifests were introduced) are only accepted if allowMissingManifest is set.
+func VerifyArtifacts(name string, circuit frontend.Circuit, ccs constraint.ConstraintSystem, pk groth16.ProvingKey, csPath, pkPath string, allowMissingManifest bool) error {
+	if err := checkCircuit(circuit, ccs, csPath); err != nil {
+		return NewError(KindArtifact, "checking constraint system", fmt.Errorf("%s: %w", csPath, err))
+	}
+	if err := checkProvingKey(ccs, pk); err != nil {
+		return NewError(KindArtifact, "checking proving key", fmt.Errorf("%s: %w", pkPath, err))
+	}
+
+	manifestPath := ManifestPath(filepath.Dir(csPath), name)
+	manifest, err := ReadManifest(manifestPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if !allowMissingManifest {
+			return NewError(KindArtifact, "reading manifest", fmt.Errorf("%s is missing, extract the artifacts again or allow missing manifests", manifestPath))
+		}
+		fmt.Fprintf(os.Stderr, "warning: %s is missing, the proving key of %s is not checked against it\n", manifestPath, name)
+		return nil
+	}
+	if err != nil {
+		return NewError(KindArtifact, "reading manifest", err)
+	}
+
+	if err := manifest.check(name, ccs, csPath, pkPath); err != nil {
+		return NewError(KindArtifact, "checking manifest", fmt.Errorf("%s: %w", manifestPath, err))
+	}
+	return nil
+}
+
+func (m *Manifest) check(name string, ccs constraint.ConstraintSystem, csPath, pkPath string) error {
+	if m.Circuit != name {
+		return fmt.Errorf("manifest is for circuit %s, not %s", m.Circuit, name)
+	}
+	if m.GnarkVersion != gnark.Version.String() {
+		return fmt.Errorf("artifacts were generated with gnark %s, running %s", m.GnarkVersion, gnark.Version)
+	}
+	if nb := ccs.GetNbConstraints(); nb != m.NbConstraints {
+		return fmt.Errorf("constraint system has %d constraints, manifest records %d", nb, m.NbConstraints)
+	}
+	if nb := ccs.GetNbPublicVariables() - 1; nb != m.NbPublicInputs {
+		return fmt.Errorf("constraint system has %d public inputs, manifest records %d", nb, m.NbPublicInputs)
+	}
+
+	for _, path := range []string{csPath, pkPath} {
+		expected, ok := m.Files[filepath.Base(path)]
+		if !ok {
+			return fmt.Errorf("%s is not listed", filepath.Base(path))
+		}
+		sum, err := sha256File(path)
+		if err != nil {
+			return err
+		}
+		if sum != expected {
+			return fmt.Errorf("%s sha256 %s does not match %s", filepath.Base(path), sum, expected)
+		}
+	}
+	return nil
+}
+
+// the constraint system must be the one the circuit compiles to: compilation is deterministic, so the file
+// is compared with the serialization of a freshly compiled constraint system
+func checkCircuit(circuit frontend.Circuit, ccs constraint.ConstraintSystem, csPath string) error {
+	fields, err := utils.CircuitFields(circuit)
+	if err != nil {
+		return err
+	}
+
+	nbPublic := 0
+	for _, field := range fields {
+		if field.Public {
+			nbPublic++
+		}
+	}
+
+	if nb := ccs.GetNbPublicVariables() - 1; nb != nbPublic {
+		return fmt.Errorf("%d public inputs, the circuit has %d", nb, nbPublic)
+	}
+
+	compiled, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+	if err != nil {
+		return fmt.Errorf("compiling circuit: %w", err)
+	}
+	if nb, expected := ccs.GetNbConstraints(), compiled.GetNbConstraints(); nb != expected {
+		return fmt.Errorf("%d constraints, the circuit compiles to %d", nb, expected)
+	}
+
+	var buf bytes.Buffer
+	if _, err := compiled.WriteTo(&buf); err != nil {
+		return fmt.Errorf("serializing circuit: %w", err)
+	}
+	expected := sha256.Sum256(buf.Bytes())
+	sum, err := sha256File(csPath)
+	if err != nil {
+		return err
+	}
+	if sum != hex.EncodeToString(expected[:]) {
+		return fmt.Errorf("sha256 %s, the circuit compiles to %x", sum, expected)
+	}
+	return nil
+}
+
+// the proving key must have been generated for this constraint system, same checks as the shapes
+// groth16.Setup and mpcsetup.ExtractKeys produce
+func checkProvingKey(ccs constraint.ConstraintSystem, pk groth16.ProvingKey) error {
+	key, ok := pk.(*groth16_bn254.ProvingKey)
+	if !ok {
+		return errors.New("proving key is not defined over bn254")
+	}
+
+	nbPublic := ccs.GetNbPublicVariables()
+	nbWires := nbPublic + ccs.GetNbSecretVariables() + ccs.GetNbInternalVariables()
+
+	if len(key.InfinityA) != nbWires {
+		return fmt.Errorf("proving key is for %d wires, the constraint system has %d", len(key.InfinityA), nbWires)
+	}
+	if len(key.G1.K) != nbWires-nbPublic {
+		return fmt.Errorf("proving key is for %d private wires, the constraint system has %d", len(key.G1.K), nbWires-nbPublic)
+	}
+	if domain := ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints())); key.Domain.Cardinality != domain {
+		return fmt.Errorf("proving key domain has size %d, the constraint system needs %d", key.Domain.Cardinality, domain)
+	}
+	return nil
+}
+
+func sha256File(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,107 @@
+// Package keys derives, signs and stores the keys of eERC users
+package keys
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	iden3babyjub "github.com/iden3/go-iden3-crypto/babyjub"
+	"golang.org/x/crypto/sha3"
+)
+
+// SignatureSize is the size of an Ethereum signature r || s || v
+const SignatureSize = 65
+
+// RegistrationMessage returns the message the frontend asks the wallet to sign to derive the eERC key of the address,
+// the fixed prefix separates it from any other message the wallet signs
+func RegistrationMessage(address string) string {
+	return "eERC\nRegistering user with\n Address:" + strings.ToLower(address)
+}
+
+// DerivedKey is the babyjub key derived from a wallet signature
+type DerivedKey struct {
+	// the scalar hashed from the signature (i0 in the frontend)
+	Seed *big.Int
+	// the key used by the circuits, formatPrivKeyForBabyJub(Seed) mod BasePointOrder
+	PrivateKey *big.Int
+	PublicKey  babyjub.Point
+}
+
+// DeriveKey maps the signature of the registration message to the babyjub key the frontend derives from it
+func DeriveKey(signature []byte) (*DerivedKey, error) {
+	seed, err := SignatureSeed(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey := FormatPrivateKey(seed)
+	publicKey, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &DerivedKey{Seed: seed, PrivateKey: privateKey, PublicKey: publicKey}, nil
+}
+
+// SignatureSeed hashes the signature to a scalar in [1, BasePointOrder) like i0 in the frontend:
+// keccak256 of the signature clamped as an ed25519 scalar, read little endian and reduced
+func SignatureSeed(signature []byte) (*big.Int, error) {
+	if len(signature) != SignatureSize {
+		return nil, fmt.Errorf("signature must be %d bytes, got %d", SignatureSize, len(signature))
+	}
+
+	h := sha3.NewLegacyKeccak256()
+	h.Write(signature)
+	digest := h.Sum(nil)
+
+	digest[0] &= 0xf8
+	digest[31] &= 0x7f
+	digest[31] |= 0x40
+
+	seed := new(big.Int).SetBytes(reverse(digest))
+	seed.Mod(seed, babyjub.BasePointOrder)
+	if seed.Sign() == 0 {
+		seed.SetInt64(1)
+	}
+	return seed, nil
+}
+
+// FormatPrivateKey returns formatPrivKeyForBabyJub(seed) mod BasePointOrder as computed by maci-crypto:
+// blake512 of the shortest big endian encoding of the seed, the first 32 bytes pruned, read little endian
+// and divided by 8
+func FormatPrivateKey(seed *big.Int) *big.Int {
+	digest := iden3babyjub.Blake512(seed.Bytes())
+
+	var buf [32]byte
+	copy(buf[:], digest[:32])
+	buf[0] &= 0xf8
+	buf[31] &= 0x7f
+	buf[31] |= 0x40
+
+	s := new(big.Int).SetBytes(reverse(buf[:]))
+	s.Rsh(s, 3)
+	return s.Mod(s, babyjub.BasePointOrder)
+}
+
+// ParseSignature reads a 0x prefixed hexadecimal signature as returned by signMessage
+func ParseSignature(s string) ([]byte, error) {
+	signature, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	if len(signature) != SignatureSize {
+		return nil, errors.New("signature must be 65 bytes r || s || v")
+	}
+	return signature, nil
+}
+
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[i] = b[len(b)-1-i]
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package keys
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+)
+
+// the keys deriveKeysFromUser returns for the signatures of the registration message by the Hardhat accounts:
+// i0(signature), formatPrivKeyForBabyJub(i0) % subOrder and the public key. The signatures are the ones of
+// go-ethereum's crypto.Sign, which signs like ethers, and the keys follow src/utils/utils.ts step by step.
+var derivedKeys = []struct {
+	signature, seed, privateKey, x, y string
+}{
+	{
+		"507b4df5b84e35de7e9de4d4e41d89f378848cd404b2a2061b6c4de16e7da842737d9838bcc5b9533066f6a506c81a1ad8ada71c38372e8716f53084e88b5d4d1c",
+		"2495944121366866709521777148631112904568761742485622361220220171074990956454",
+		"1904100126804754744423257898767899699455774259129383713310861805973400583481",
+		"18518970867172345484743397853133961693979330885853409178692751183754521715532",
+		"7549796617800983772505781354059740476826808281632000058768501215781792371544",
+	},
+	{
+		"e4ad5577ea9e856fe3b2017ee0bd9c765398b379e67b0357c9b5749840d4a6f4103a3f1bf42a493794bbfc0d55b1f3a281dc986cd483de4b828999d330782b9e1c",
+		"627717497432936109077490755197387333296610165288077435398258413865378863850",
+		"694900830982648197709328292873997866931683828817048232948242993403022322113",
+		"663711040460045797000650140021336255530526778453025760648665105468697337725",
+		"7134587461147854263250401026940165494892907298482184558817945227289880533939",
+	},
+}
+
+func TestDeriveKey(t *testing.T) {
+	for i, vector := range derivedKeys {
+		signature, err := ParseSignature("0x" + vector.signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := DeriveKey(signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key.Seed.String() != vector.seed {
+			t.Errorf("vector %d: seed %s, expected %s", i, key.Seed, vector.seed)
+		}
+		if key.PrivateKey.String() != vector.privateKey {
+			t.Errorf("vector %d: private key %s, expected %s", i, key.PrivateKey, vector.privateKey)
+		}
+		if key.PublicKey.X.String() != vector.x || key.PublicKey.Y.String() != vector.y {
+			t.Errorf("vector %d: public key %s, expected (%s, %s)", i, key.PublicKey, vector.x, vector.y)
+		}
+	}
+}
+
+func TestDeriveKeyFromAccount(t *testing.T) {
+	for i, account := range hardhatAccounts {
+		k, err := ParseEthereumKey(account.privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := DeriveKey(k.SignMessage(RegistrationMessage(k.Address())))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key.PrivateKey.String() != derivedKeys[i].privateKey {
+			t.Fatalf("account %d: private key %s, expected %s", i, key.PrivateKey, derivedKeys[i].privateKey)
+		}
+	}
+}
+
+func TestSignatureSeed(t *testing.T) {
+	if _, err := SignatureSeed(make([]byte, SignatureSize-1)); err == nil {
+		t.Error("short signature accepted")
+	}
+
+	for _, vector := range derivedKeys {
+		signature, _ := hex.DecodeString(vector.signature)
+		seed, err := SignatureSeed(signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seed.Sign() <= 0 || seed.Cmp(babyjub.BasePointOrder) >= 0 {
+			t.Fatalf("seed %s out of [1, BasePointOrder)", seed)
+		}
+	}
+}
+
+func TestFormatPrivateKey(t *testing.T) {
+	// the private key is reduced below the order, pruning leaves at most 251 bits after the shift
+	for _, seed := range []*big.Int{big.NewInt(1), big.NewInt(255), new(big.Int).Sub(babyjub.BasePointOrder, big.NewInt(1))} {
+		privateKey := FormatPrivateKey(seed)
+		if err := babyjub.CheckPrivateKey(privateKey); err != nil {
+			t.Fatalf("seed %s: %v", seed, err)
+		}
+	}
+}
+
+func TestRegistrationMessage(t *testing.T) {
+	message := RegistrationMessage("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	if message != "eERC\nRegistering user with\n Address:0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266" {
+		t.Fatalf("message %q", message)
+	}
+}
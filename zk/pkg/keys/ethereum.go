@@ -0,0 +1,186 @@
+package keys
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fr"
+	"golang.org/x/crypto/sha3"
+)
+
+// EthereumKey is a secp256k1 private key signing messages like an Ethereum wallet
+type EthereumKey struct {
+	d      *big.Int
+	public secp256k1.G1Affine
+}
+
+// NewEthereumKey returns the key of the secp256k1 scalar, which must be in [1, n)
+func NewEthereumKey(d *big.Int) (*EthereumKey, error) {
+	if d == nil || d.Sign() <= 0 || d.Cmp(fr.Modulus()) >= 0 {
+		return nil, errors.New("secp256k1 private key must be in [1, n)")
+	}
+	k := &EthereumKey{d: new(big.Int).Set(d)}
+	k.public.ScalarMultiplicationBase(k.d)
+	return k, nil
+}
+
+// ParseEthereumKey reads a hexadecimal private key, 0x prefixed or not, as exported by wallets
+func ParseEthereumKey(s string) (*EthereumKey, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+	if err != nil || len(b) != 32 {
+		return nil, errors.New("private key must be 32 hexadecimal bytes")
+	}
+	return NewEthereumKey(new(big.Int).SetBytes(b))
+}
+
+// ReadEthereumKey reads the hexadecimal private key stored in the file
+func ReadEthereumKey(path string) (*EthereumKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	k, err := ParseEthereumKey(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return k, nil
+}
+
+// Address returns the EIP-55 checksummed address of the key
+func (k *EthereumKey) Address() string {
+	x, y := k.public.X.Bytes(), k.public.Y.Bytes()
+	digest := keccak256(x[:], y[:])
+	return checksumAddress(hex.EncodeToString(digest[12:]))
+}
+
+// SignMessage signs the EIP-191 hash of the message like signMessage of ethers: the nonce is derived
+// with RFC 6979 and s is normalized to the lower half, so the signature of a message is always the same
+// and a key derived from it can be recovered by signing again. The signature is r || s || v with v in {27, 28}.
+func (k *EthereumKey) SignMessage(message string) []byte {
+	return k.sign(HashMessage(message))
+}
+
+// HashMessage returns keccak256("\x19Ethereum Signed Message:\n" || len(message) || message)
+func HashMessage(message string) []byte {
+	prefix := "\x19Ethereum Signed Message:\n" + strconv.Itoa(len(message))
+	return keccak256([]byte(prefix), []byte(message))
+}
+
+func (k *EthereumKey) sign(hash []byte) []byte {
+	n := fr.Modulus()
+	halfN := new(big.Int).Rsh(n, 1)
+	z := new(big.Int).SetBytes(hash)
+	z.Mod(z, n)
+
+	nonces := rfc6979(k.d, z)
+	for {
+		nonce := nonces()
+
+		var R secp256k1.G1Affine
+		R.ScalarMultiplicationBase(nonce)
+		rx := R.X.BigInt(new(big.Int))
+		r := new(big.Int).Mod(rx, n)
+		if r.Sign() == 0 {
+			continue
+		}
+
+		// s = nonce⁻¹·(z + r·d)
+		s := new(big.Int).Mul(r, k.d)
+		s.Add(s, z)
+		s.Mul(s, new(big.Int).ModInverse(nonce, n))
+		s.Mod(s, n)
+		if s.Sign() == 0 {
+			continue
+		}
+
+		recovery := byte(R.Y.BigInt(new(big.Int)).Bit(0))
+		if rx.Cmp(n) >= 0 {
+			recovery |= 2
+		}
+		if s.Cmp(halfN) > 0 {
+			s.Sub(n, s)
+			recovery ^= 1
+		}
+
+		signature := make([]byte, SignatureSize)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:64])
+		signature[64] = 27 + recovery
+		return signature
+	}
+}
+
+// returns the successive candidate nonces of RFC 6979 with HMAC-SHA256 for the key and the reduced hash
+func rfc6979(d, z *big.Int) func() *big.Int {
+	n := fr.Modulus()
+	var x, h [32]byte
+	d.FillBytes(x[:])
+	z.FillBytes(h[:])
+
+	mac := func(key []byte, data ...[]byte) []byte {
+		m := hmac.New(sha256.New, key)
+		for _, b := range data {
+			m.Write(b)
+		}
+		return m.Sum(nil)
+	}
+
+	V := make([]byte, 32)
+	K := make([]byte, 32)
+	for i := range V {
+		V[i] = 0x01
+	}
+	K = mac(K, V, []byte{0x00}, x[:], h[:])
+	V = mac(K, V)
+	K = mac(K, V, []byte{0x01}, x[:], h[:])
+	V = mac(K, V)
+
+	first := true
+	return func() *big.Int {
+		for {
+			if !first {
+				K = mac(K, V, []byte{0x00})
+				V = mac(K, V)
+			}
+			first = false
+
+			V = mac(K, V)
+			nonce := new(big.Int).SetBytes(V)
+			if nonce.Sign() > 0 && nonce.Cmp(n) < 0 {
+				return nonce
+			}
+		}
+	}
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// EIP-55: the letters of the address are upper cased where the nibble of keccak256(address) is at least 8
+func checksumAddress(lower string) string {
+	digest := keccak256([]byte(lower))
+	out := []byte(lower)
+	for i, c := range out {
+		nibble := digest[i/2] >> 4
+		if i%2 == 1 {
+			nibble = digest[i/2] & 0x0f
+		}
+		if c >= 'a' && nibble >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
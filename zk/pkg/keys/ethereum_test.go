@@ -0,0 +1,105 @@
+package keys
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// default accounts of the Hardhat network, from the "test test ... junk" mnemonic
+var hardhatAccounts = []struct {
+	privateKey, address string
+}{
+	{"ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"},
+	{"59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d", "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"},
+}
+
+func TestAddress(t *testing.T) {
+	for _, account := range hardhatAccounts {
+		k, err := ParseEthereumKey("0x" + account.privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if address := k.Address(); address != account.address {
+			t.Fatalf("address %s, expected %s", address, account.address)
+		}
+	}
+}
+
+func TestChecksumAddress(t *testing.T) {
+	// examples of EIP-55
+	for _, address := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		if checksummed := checksumAddress(strings.ToLower(address[2:])); checksummed != address {
+			t.Fatalf("checksummed %s, expected %s", checksummed, address)
+		}
+	}
+}
+
+func TestHashMessage(t *testing.T) {
+	// hashMessage of ethers
+	hash := HashMessage("hello world")
+	if got := hex.EncodeToString(hash); got != "d9eba16ed0ecae432b71fe008c98cc872bb4cc214d3220a36f365326cf807d68" {
+		t.Fatalf("hash %s", got)
+	}
+}
+
+func TestRFC6979(t *testing.T) {
+	// secp256k1 with SHA-256 for the key 1 and the message "Satoshi Nakamoto", the vector of python-ecdsa and trezor-crypto
+	k, err := NewEthereumKey(big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("Satoshi Nakamoto"))
+
+	nonce := rfc6979(big.NewInt(1), new(big.Int).SetBytes(hash[:]))()
+	if got := hex.EncodeToString(nonce.Bytes()); got != "8f8a276c19f4149656b280621e358cce24f5f52542772691ee69063b74f15d15" {
+		t.Fatalf("nonce %s", got)
+	}
+
+	signature := k.sign(hash[:])
+	r := "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8"
+	s := "2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5"
+	if got := hex.EncodeToString(signature); got != r+s+"1c" {
+		t.Fatalf("signature %s", got)
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	// signMessage of ethers, s is in the lower half and v in {27, 28}
+	expected := []string{
+		"507b4df5b84e35de7e9de4d4e41d89f378848cd404b2a2061b6c4de16e7da842737d9838bcc5b9533066f6a506c81a1ad8ada71c38372e8716f53084e88b5d4d1c",
+		"e4ad5577ea9e856fe3b2017ee0bd9c765398b379e67b0357c9b5749840d4a6f4103a3f1bf42a493794bbfc0d55b1f3a281dc986cd483de4b828999d330782b9e1c",
+	}
+	for i, account := range hardhatAccounts {
+		k, err := ParseEthereumKey(account.privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature := k.SignMessage(RegistrationMessage(account.address))
+		if got := hex.EncodeToString(signature); got != expected[i] {
+			t.Fatalf("account %d: signature %s, expected %s", i, got, expected[i])
+		}
+	}
+}
+
+func TestParseEthereumKey(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"0x01",
+		"zz0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80",
+		strings.Repeat("00", 32),
+		// the order of secp256k1
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141",
+	} {
+		if _, err := ParseEthereumKey(s); err == nil {
+			t.Errorf("private key %q accepted", s)
+		}
+	}
+}
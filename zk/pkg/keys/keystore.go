@@ -0,0 +1,288 @@
+package keys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keystoreVersion = 1
+	keystoreCipher  = "aes-256-gcm"
+	keystoreKDF     = "scrypt"
+)
+
+// ErrWrongPassword is returned when the keystore can not be decrypted with the password
+var ErrWrongPassword = errors.New("wrong password or corrupted keystore")
+
+// ScryptParams are the cost parameters of the key derivation
+type ScryptParams struct {
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+}
+
+var (
+	// StandardScrypt is the cost of Ethereum's v3 keystores, about a second and 256MB per decryption
+	StandardScrypt = ScryptParams{N: 1 << 18, R: 8, P: 1}
+	// LightScrypt is the cheaper cost of Ethereum's light keystores, for keys that only hold test funds
+	LightScrypt = ScryptParams{N: 1 << 12, R: 8, P: 6}
+)
+
+const (
+	// the cost of a keystore is read from the file before the password is checked, a larger one is
+	// refused so a crafted keystore can not exhaust the memory or the time of the prover
+	maxScryptN  = 1 << 18
+	maxScryptRP = 64
+	// bytes of memory scrypt needs, 128·N·R, at most that of StandardScrypt
+	maxScryptMemory = 128 * maxScryptN * 8
+)
+
+// Check returns an error if the parameters are not valid scrypt parameters or cost more than StandardScrypt
+// in memory, or more than 64 times its block mixing
+func (p ScryptParams) Check() error {
+	if p.N <= 1 || p.N&(p.N-1) != 0 {
+		return fmt.Errorf("scrypt n %d is not a power of two above 1", p.N)
+	}
+	if p.N > maxScryptN {
+		return fmt.Errorf("scrypt n %d is above %d", p.N, maxScryptN)
+	}
+	if p.R < 1 || p.P < 1 || p.R > maxScryptRP || p.P > maxScryptRP || p.R*p.P > maxScryptRP {
+		return fmt.Errorf("scrypt r %d and p %d must be positive with r·p at most %d", p.R, p.P, maxScryptRP)
+	}
+	if 128*p.N*p.R > maxScryptMemory {
+		return fmt.Errorf("scrypt n %d and r %d need more than %dMB", p.N, p.R, maxScryptMemory>>20)
+	}
+	return nil
+}
+
+/*
+Keystore is the encrypted file holding a babyjub private key, modeled on Ethereum's v3 keystore
+
+	{
+		"version": 1, "address": "0x...", "chainId": "43113", "publicKey": ["x", "y"],
+		"crypto": { "cipher": "aes-256-gcm", "ciphertext": "...", "nonce": "...",
+			"kdf": "scrypt", "kdfparams": { "n": 262144, "r": 8, "p": 1, "salt": "..." } }
+	}
+
+The private key is encrypted with AES-GCM under a key derived from the password with scrypt. The public part
+(version, address, chain id and public key) is authenticated as additional data so it can be read without
+the password but not altered.
+*/
+type Keystore struct {
+	Version   int            `json:"version"`
+	Address   string         `json:"address,omitempty"`
+	ChainID   string         `json:"chainId,omitempty"`
+	PublicKey babyjub.Point  `json:"publicKey"`
+	Crypto    KeystoreCrypto `json:"crypto"`
+}
+
+type KeystoreCrypto struct {
+	Cipher     string    `json:"cipher"`
+	CipherText string    `json:"ciphertext"`
+	Nonce      string    `json:"nonce"`
+	KDF        string    `json:"kdf"`
+	KDFParams  KDFParams `json:"kdfparams"`
+}
+
+type KDFParams struct {
+	ScryptParams
+	Salt string `json:"salt"`
+}
+
+// EncryptKey encrypts the private key with the password, the address and the chain id it is registered for are optional
+func EncryptKey(privateKey *big.Int, address string, chainID *big.Int, password []byte, params ScryptParams) (*Keystore, error) {
+	publicKey, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &Keystore{Version: keystoreVersion, Address: address, PublicKey: publicKey}
+	if chainID != nil {
+		ks.ChainID = chainID.String()
+	}
+	if err := ks.encrypt(privateKey, password, params); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Decrypt returns the private key, it fails with ErrWrongPassword if the password is wrong
+// or the keystore was modified
+func (ks *Keystore) Decrypt(password []byte) (*big.Int, error) {
+	if ks.Version != keystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version %d", ks.Version)
+	}
+	if ks.Crypto.Cipher != keystoreCipher || ks.Crypto.KDF != keystoreKDF {
+		return nil, fmt.Errorf("unsupported keystore cipher %s with %s", ks.Crypto.Cipher, ks.Crypto.KDF)
+	}
+
+	if err := ks.Crypto.KDFParams.Check(); err != nil {
+		return nil, fmt.Errorf("unsupported keystore kdf: %w", err)
+	}
+
+	salt, err := hex.DecodeString(ks.Crypto.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	nonce, err := hex.DecodeString(ks.Crypto.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	aead, err := newAEAD(password, salt, ks.Crypto.KDFParams.ScryptParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid nonce")
+	}
+	ad, err := ks.additionalData()
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, ad)
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	privateKey := new(big.Int).SetBytes(plaintext)
+	publicKey, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if !publicKey.Equal(&ks.PublicKey) {
+		return nil, errors.New("keystore public key does not match the private key")
+	}
+	return privateKey, nil
+}
+
+// ChangePassword re-encrypts the private key with the new password, with a fresh salt and nonce
+func (ks *Keystore) ChangePassword(oldPassword, newPassword []byte, params ScryptParams) error {
+	privateKey, err := ks.Decrypt(oldPassword)
+	if err != nil {
+		return err
+	}
+	return ks.encrypt(privateKey, newPassword, params)
+}
+
+// ReadKeystore reads the keystore from the file
+func ReadKeystore(path string) (*Keystore, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ks Keystore
+	if err := json.Unmarshal(raw, &ks); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &ks, nil
+}
+
+// Save writes the keystore to the file, readable by the owner only, an existing keystore is never overwritten
+func (ks *Keystore) Save(path string) error {
+	out, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(out); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Replace writes the keystore over the existing file, through a temporary file renamed over it
+// so the key is not lost if writing fails
+func (ks *Keystore) Replace(path string) error {
+	tmp := path + ".tmp"
+	if err := ks.Save(tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (ks *Keystore) encrypt(privateKey *big.Int, password []byte, params ScryptParams) error {
+	if err := params.Check(); err != nil {
+		return err
+	}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	aead, err := newAEAD(password, salt, params)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	ks.Crypto = KeystoreCrypto{
+		Cipher: keystoreCipher,
+		Nonce:  hex.EncodeToString(nonce),
+		KDF:    keystoreKDF,
+		KDFParams: KDFParams{
+			ScryptParams: params,
+			Salt:         hex.EncodeToString(salt),
+		},
+	}
+
+	ad, err := ks.additionalData()
+	if err != nil {
+		return err
+	}
+	plaintext := make([]byte, 32)
+	privateKey.FillBytes(plaintext)
+	ks.Crypto.CipherText = hex.EncodeToString(aead.Seal(nil, nonce, plaintext, ad))
+	return nil
+}
+
+// the public part of the keystore and the kdf parameters, so a weaker kdf can not be swapped in either
+func (ks *Keystore) additionalData() ([]byte, error) {
+	return json.Marshal(struct {
+		Version   int           `json:"version"`
+		Address   string        `json:"address"`
+		ChainID   string        `json:"chainId"`
+		PublicKey babyjub.Point `json:"publicKey"`
+		KDFParams KDFParams     `json:"kdfparams"`
+	}{ks.Version, ks.Address, ks.ChainID, ks.PublicKey, ks.Crypto.KDFParams})
+}
+
+func newAEAD(password, salt []byte, params ScryptParams) (cipher.AEAD, error) {
+	key, err := scrypt.Key(password, salt, params.N, params.R, params.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,66 @@
+package keys
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	privateKey := big.NewInt(123456789)
+	ks, err := EncryptKey(privateKey, "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266", big.NewInt(31337), []byte("password"), LightScrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := ks.Decrypt([]byte("password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.Cmp(privateKey) != 0 {
+		t.Fatalf("decrypted %s", decrypted)
+	}
+	if _, err := ks.Decrypt([]byte("wrong")); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("wrong password: %v", err)
+	}
+
+	// the public part is authenticated
+	ks.ChainID = "1"
+	if _, err := ks.Decrypt([]byte("password")); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("altered chain id: %v", err)
+	}
+}
+
+func TestScryptParamsCheck(t *testing.T) {
+	for _, params := range []ScryptParams{StandardScrypt, LightScrypt, {N: 2, R: 1, P: 1}, {N: 1 << 12, R: 1, P: 64}} {
+		if err := params.Check(); err != nil {
+			t.Errorf("%+v: %v", params, err)
+		}
+	}
+	for _, params := range []ScryptParams{
+		{N: 1 << 19, R: 8, P: 1},
+		{N: 1<<18 + 1, R: 8, P: 1},
+		{N: 1, R: 8, P: 1},
+		{N: 0, R: 8, P: 1},
+		{N: 1 << 12, R: 0, P: 1},
+		{N: 1 << 12, R: 8, P: -1},
+		{N: 1 << 12, R: 8, P: 9},
+		{N: 1 << 12, R: 1 << 30, P: 1 << 30},
+		{N: 1 << 18, R: 16, P: 1},
+	} {
+		if err := params.Check(); err == nil {
+			t.Errorf("%+v accepted", params)
+		}
+	}
+}
+
+func TestDecryptRejectsCostlyKDF(t *testing.T) {
+	ks, err := EncryptKey(big.NewInt(1), "", nil, []byte("password"), LightScrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rejected before any key derivation, the altered parameters would also fail the authentication
+	ks.Crypto.KDFParams.N = 1 << 30
+	if _, err := ks.Decrypt([]byte("password")); err == nil || errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("n=2^30: %v", err)
+	}
+}
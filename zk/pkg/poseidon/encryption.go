@@ -0,0 +1,116 @@
+package poseidon
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+var two128 = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// Encrypt encrypts the message with the shared key and nonce, the native counterpart of poseidonDecrypt
+// and compatible with circomlib/maci poseidonEncrypt. The ciphertext holds ⌈len/3⌉·3 + 1 elements.
+func Encrypt(message []*big.Int, key *babyjub.Point, nonce *big.Int) ([]*big.Int, error) {
+	state, err := initialState(key, nonce, len(message))
+	if err != nil {
+		return nil, err
+	}
+
+	padded, err := toFieldElements(message)
+	if err != nil {
+		return nil, err
+	}
+	for len(padded)%3 != 0 {
+		padded = append(padded, fr.Element{})
+	}
+
+	ciphertext := make([]*big.Int, 0, len(padded)+1)
+	for i := 0; i < len(padded); i += 3 {
+		state = permute(state[1:], state[0], 4)
+		for j := 0; j < 3; j++ {
+			state[j+1].Add(&state[j+1], &padded[i+j])
+			ciphertext = append(ciphertext, state[j+1].BigInt(new(big.Int)))
+		}
+	}
+
+	state = permute(state[1:], state[0], 4)
+	ciphertext = append(ciphertext, state[1].BigInt(new(big.Int)))
+	return ciphertext, nil
+}
+
+// Decrypt decrypts a ciphertext of a message of the given length, it fails like the circuit
+// when the padding is not zero or the last element does not authenticate the ciphertext
+func Decrypt(ciphertext []*big.Int, key *babyjub.Point, nonce *big.Int, length int) ([]*big.Int, error) {
+	if length < 1 {
+		return nil, errors.New("message length must be positive")
+	}
+	nbBlocks := (length + 2) / 3
+	if len(ciphertext) != nbBlocks*3+1 {
+		return nil, fmt.Errorf("expected %d ciphertext elements for a message of length %d, got %d", nbBlocks*3+1, length, len(ciphertext))
+	}
+
+	state, err := initialState(key, nonce, length)
+	if err != nil {
+		return nil, err
+	}
+	ct, err := toFieldElements(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	message := make([]*big.Int, 0, nbBlocks*3)
+	for i := 0; i < nbBlocks*3; i += 3 {
+		state = permute(state[1:], state[0], 4)
+		for j := 0; j < 3; j++ {
+			var m fr.Element
+			m.Sub(&ct[i+j], &state[j+1])
+			message = append(message, m.BigInt(new(big.Int)))
+		}
+		// the next state absorbs the ciphertext
+		copy(state[1:], ct[i:i+3])
+	}
+
+	for _, m := range message[length:] {
+		if m.Sign() != 0 {
+			return nil, errors.New("invalid ciphertext padding")
+		}
+	}
+
+	state = permute(state[1:], state[0], 4)
+	if !state[1].Equal(&ct[len(ct)-1]) {
+		return nil, errors.New("ciphertext authentication failed")
+	}
+
+	return message[:length], nil
+}
+
+// [0, key.x, key.y, nonce + length·2^128]
+func initialState(key *babyjub.Point, nonce *big.Int, length int) ([]fr.Element, error) {
+	if nonce == nil || nonce.Sign() < 0 || nonce.Cmp(two128) >= 0 {
+		return nil, errors.New("nonce must be in [0, 2^128)")
+	}
+
+	var n, l fr.Element
+	n.SetBigInt(nonce)
+	l.SetBigInt(new(big.Int).Mul(big.NewInt(int64(length)), two128))
+
+	state := make([]fr.Element, 4)
+	state[1] = key.X
+	state[2] = key.Y
+	state[3].Add(&n, &l)
+	return state, nil
+}
+
+func toFieldElements(values []*big.Int) ([]fr.Element, error) {
+	out := make([]fr.Element, len(values))
+	for i, v := range values {
+		if v == nil || v.Sign() < 0 || v.Cmp(fr.Modulus()) >= 0 {
+			return nil, fmt.Errorf("element %d is not in the scalar field", i)
+		}
+		out[i].SetBigInt(v)
+	}
+	return out, nil
+}
@@ -0,0 +1,142 @@
+package poseidon
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	iden3 "github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// poseidonEncrypt of maci-crypto, on top of the permutation of go-iden3-crypto which follows circomlibjs
+func referenceEncrypt(message []*big.Int, key *babyjub.Point, nonce *big.Int) ([]*big.Int, error) {
+	length := new(big.Int).Mul(big.NewInt(int64(len(message))), two128)
+	state := []*big.Int{big.NewInt(0), key.BigX(), key.BigY(), new(big.Int).Add(nonce, length)}
+
+	padded := append([]*big.Int{}, message...)
+	for len(padded)%3 != 0 {
+		padded = append(padded, big.NewInt(0))
+	}
+
+	var err error
+	var ciphertext []*big.Int
+	for i := 0; i < len(padded); i += 3 {
+		if state, err = iden3.HashWithStateEx(state[1:], state[0], 4); err != nil {
+			return nil, err
+		}
+		for j := 1; j <= 3; j++ {
+			state[j] = new(big.Int).Mod(new(big.Int).Add(state[j], padded[i+j-1]), fr.Modulus())
+			ciphertext = append(ciphertext, state[j])
+		}
+	}
+
+	if state, err = iden3.HashWithStateEx(state[1:], state[0], 4); err != nil {
+		return nil, err
+	}
+	return append(ciphertext, state[1]), nil
+}
+
+// poseidonEncrypt([1, 2, 3, 4], 1234·Base8, 5678) as referenceEncrypt computes it, pins the ciphertext layout
+var encryptionVector = []string{
+	"4228659618454818731805386671007401651812136759066810596159618597283940780176",
+	"11008875918718225102518326258655904694838138273419155939563573684918253705878",
+	"1079532980288844402940850911040814333592524663002933334063603524906224431130",
+	"4251670273884753205753538658690347065195000609070366501207268348869518366670",
+	"7851931856766981666444406032202991704475768477725659946971912819874508353220",
+	"5060365667289348028038141185402693372219663137407222822471905542945053118879",
+	"7762885348426179241311282337571205336111072142624333140330905061025791389676",
+}
+
+func TestEncryptVector(t *testing.T) {
+	key, err := babyjub.PublicKey(big.NewInt(1234))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4)}
+
+	ciphertext, err := Encrypt(message, &key, big.NewInt(5678))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ciphertext) != len(encryptionVector) {
+		t.Fatalf("%d ciphertext elements, expected %d", len(ciphertext), len(encryptionVector))
+	}
+	for i := range ciphertext {
+		if ciphertext[i].String() != encryptionVector[i] {
+			t.Fatalf("ciphertext[%d] = %s, expected %s", i, ciphertext[i], encryptionVector[i])
+		}
+	}
+
+	decrypted, err := Decrypt(ciphertext, &key, big.NewInt(5678), len(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range message {
+		if decrypted[i].Cmp(message[i]) != 0 {
+			t.Fatalf("decrypted[%d] = %s, expected %s", i, decrypted[i], message[i])
+		}
+	}
+}
+
+func TestEncryptMatchesReference(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for length := 1; length <= 7; length++ {
+		key, err := babyjub.PublicKey(new(big.Int).Add(new(big.Int).Rand(random, big.NewInt(1<<62)), big.NewInt(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonce := new(big.Int).Rand(random, two128)
+		message := make([]*big.Int, length)
+		for i := range message {
+			message[i] = new(big.Int).Rand(random, fr.Modulus())
+		}
+
+		ciphertext, err := Encrypt(message, &key, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := referenceEncrypt(message, &key, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range expected {
+			if ciphertext[i].Cmp(expected[i]) != 0 {
+				t.Fatalf("length %d: ciphertext[%d] = %s, reference %s", length, i, ciphertext[i], expected[i])
+			}
+		}
+
+		decrypted, err := Decrypt(ciphertext, &key, nonce, length)
+		if err != nil {
+			t.Fatalf("length %d: %v", length, err)
+		}
+		for i := range message {
+			if decrypted[i].Cmp(message[i]) != 0 {
+				t.Fatalf("length %d: decrypted[%d] = %s, expected %s", length, i, decrypted[i], message[i])
+			}
+		}
+	}
+}
+
+func TestDecryptRejectsMalformed(t *testing.T) {
+	key, err := babyjub.PublicKey(big.NewInt(1234))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := Encrypt([]*big.Int{big.NewInt(7)}, &key, big.NewInt(5678))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the message is one element, the two padding elements must decrypt to zero
+	if _, err := Decrypt(ciphertext, &key, big.NewInt(5678), 3); err == nil {
+		t.Error("decrypted a message of length 1 as length 3")
+	}
+	if _, err := Decrypt(ciphertext[:3], &key, big.NewInt(5678), 1); err == nil {
+		t.Error("decrypted a truncated ciphertext")
+	}
+	if _, err := Encrypt([]*big.Int{big.NewInt(7)}, &key, two128); err == nil {
+		t.Error("encrypted with a nonce of 2^128")
+	}
+}
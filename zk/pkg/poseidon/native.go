@@ -0,0 +1,166 @@
+package poseidon
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// constants of the permutation for a width t, converted once from the same tables the circuit uses
+type nativeConstants struct {
+	c, s []fr.Element
+	m, p [][]fr.Element
+}
+
+var (
+	nativeOnce      [18]sync.Once
+	nativeConstTabs [18]nativeConstants
+)
+
+func constantsFor(t int) *nativeConstants {
+	nativeOnce[t].Do(func() {
+		k := &nativeConstTabs[t]
+		k.c = toElements(POSEIDON_C(t))
+		k.s = toElements(POSEIDON_S(t))
+		for _, row := range POSEIDON_M(t) {
+			k.m = append(k.m, toElements(row))
+		}
+		for _, row := range POSEIDON_P(t) {
+			k.p = append(k.p, toElements(row))
+		}
+	})
+	return &nativeConstTabs[t]
+}
+
+// HashEx is the native counterpart of PoseidonEx, it returns the first nOuts elements of the permuted state
+// [initialState, inputs...] computed with the same constants as the circuit (circomlib's PoseidonEx)
+func HashEx(inputs []*big.Int, initialState *big.Int, nOuts int) ([]*big.Int, error) {
+	if len(inputs) < 1 || len(inputs) > 16 {
+		return nil, errors.New("poseidon takes 1 to 16 inputs")
+	}
+	if nOuts < 1 || nOuts > len(inputs)+1 {
+		return nil, errors.New("poseidon outputs at most the width of its state")
+	}
+
+	elements, err := toFieldElements(append([]*big.Int{initialState}, inputs...))
+	if err != nil {
+		return nil, err
+	}
+
+	state := permute(elements[1:], elements[0], nOuts)
+	out := make([]*big.Int, nOuts)
+	for i := range state {
+		out[i] = state[i].BigInt(new(big.Int))
+	}
+	return out, nil
+}
+
+// Hash returns the Poseidon hash of the inputs like Hash2 and Hash3 in the circuits,
+// the registration hash is Hash(chainID, privateKey, address)
+func Hash(inputs ...*big.Int) (*big.Int, error) {
+	out, err := HashEx(inputs, new(big.Int), 1)
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+// permute is the native counterpart of PoseidonEx, it follows the circuit step by step
+func permute(inputs []fr.Element, initialState fr.Element, nOuts int) []fr.Element {
+	nRoundsPC := [16]int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68}
+	t := len(inputs) + 1
+	nRoundsF := 8
+	nRoundsP := nRoundsPC[t-2]
+	k := constantsFor(t)
+
+	state := make([]fr.Element, t)
+	state[0] = initialState
+	copy(state[1:], inputs)
+	ark(state, k.c, 0)
+
+	for r := 0; r < nRoundsF/2-1; r++ {
+		sigmaAll(state)
+		ark(state, k.c, (r+1)*t)
+		state = mix(state, k.m)
+	}
+
+	sigmaAll(state)
+	ark(state, k.c, nRoundsF/2*t)
+	state = mix(state, k.p)
+
+	for r := 0; r < nRoundsP; r++ {
+		sigma(&state[0])
+		state[0].Add(&state[0], &k.c[(nRoundsF/2+1)*t+r])
+
+		var newState0, mul fr.Element
+		for j := range state {
+			mul.Mul(&k.s[(t*2-1)*r+j], &state[j])
+			newState0.Add(&newState0, &mul)
+		}
+
+		for i := 1; i < t; i++ {
+			mul.Mul(&state[0], &k.s[(t*2-1)*r+t+i-1])
+			state[i].Add(&state[i], &mul)
+		}
+		state[0] = newState0
+	}
+
+	for r := 0; r < nRoundsF/2-1; r++ {
+		sigmaAll(state)
+		ark(state, k.c, (nRoundsF/2+1)*t+nRoundsP+r*t)
+		state = mix(state, k.m)
+	}
+
+	sigmaAll(state)
+
+	out := make([]fr.Element, nOuts)
+	for i := range out {
+		var mul fr.Element
+		for j := range state {
+			mul.Mul(&k.m[j][i], &state[j])
+			out[i].Add(&out[i], &mul)
+		}
+	}
+	return out
+}
+
+func sigma(e *fr.Element) {
+	var e2, e4 fr.Element
+	e2.Square(e)
+	e4.Square(&e2)
+	e.Mul(&e4, e)
+}
+
+func sigmaAll(state []fr.Element) {
+	for i := range state {
+		sigma(&state[i])
+	}
+}
+
+func ark(state []fr.Element, c []fr.Element, r int) {
+	for i := range state {
+		state[i].Add(&state[i], &c[i+r])
+	}
+}
+
+func mix(state []fr.Element, m [][]fr.Element) []fr.Element {
+	out := make([]fr.Element, len(state))
+	for i := range out {
+		var mul fr.Element
+		for j := range state {
+			mul.Mul(&m[j][i], &state[j])
+			out[i].Add(&out[i], &mul)
+		}
+	}
+	return out
+}
+
+func toElements(values []*big.Int) []fr.Element {
+	out := make([]fr.Element, len(values))
+	for i, v := range values {
+		out[i].SetBigInt(v)
+	}
+	return out
+}
@@ -0,0 +1,196 @@
+package poseidon
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	iden3 "github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// computes PoseidonEx in the circuit and asserts the outputs
+type poseidonExCircuit struct {
+	Inputs       []frontend.Variable
+	InitialState frontend.Variable
+	Outputs      []frontend.Variable
+}
+
+func (c *poseidonExCircuit) Define(api frontend.API) error {
+	out := PoseidonEx(api, c.Inputs, c.InitialState, len(c.Outputs))
+	for i := range out {
+		api.AssertIsEqual(out[i], c.Outputs[i])
+	}
+	return nil
+}
+
+// inputs of every width: small values, the largest field element and random field elements
+func testInputs(n int, random *rand.Rand) [][]*big.Int {
+	small := make([]*big.Int, n)
+	largest := make([]*big.Int, n)
+	sampled := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		small[i] = big.NewInt(int64(i + 1))
+		largest[i] = new(big.Int).Sub(fr.Modulus(), big.NewInt(1))
+		sampled[i] = new(big.Int).Rand(random, fr.Modulus())
+	}
+	return [][]*big.Int{small, largest, sampled}
+}
+
+func TestHashMatchesIden3(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for n := 1; n <= 16; n++ {
+		for _, inputs := range testInputs(n, random) {
+			h, err := Hash(inputs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := iden3.Hash(inputs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h.Cmp(expected) != 0 {
+				t.Fatalf("t=%d inputs %v: hash %s, iden3 %s", n+1, inputs, h, expected)
+			}
+		}
+	}
+
+	// circomlibjs poseidon([1, 2])
+	h, err := Hash(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.String() != "7853200120776062878684798364095072458815029376092732009249414926327459813530" {
+		t.Fatalf("poseidon([1, 2]) = %s", h)
+	}
+}
+
+func TestHashExMatchesIden3(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	for n := 1; n <= 16; n++ {
+		inputs := testInputs(n, random)[2]
+		initialState := new(big.Int).Rand(random, fr.Modulus())
+		for _, nOuts := range []int{1, n + 1} {
+			out, err := HashEx(inputs, initialState, nOuts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := iden3.HashWithStateEx(inputs, initialState, nOuts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range expected {
+				if out[i].Cmp(expected[i]) != 0 {
+					t.Fatalf("t=%d output %d of %d: %s, iden3 %s", n+1, i, nOuts, out[i], expected[i])
+				}
+			}
+		}
+	}
+}
+
+func TestHashMatchesCircuit(t *testing.T) {
+	random := rand.New(rand.NewSource(3))
+	for n := 1; n <= 16; n++ {
+		inputs := testInputs(n, random)[2]
+		initialState := new(big.Int).Rand(random, fr.Modulus())
+		nOuts := min(n+1, 4)
+		out, err := HashEx(inputs, initialState, nOuts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		circuit := &poseidonExCircuit{Inputs: make([]frontend.Variable, n), Outputs: make([]frontend.Variable, nOuts)}
+		assignment := &poseidonExCircuit{InitialState: initialState, Inputs: make([]frontend.Variable, n), Outputs: make([]frontend.Variable, nOuts)}
+		for i, v := range inputs {
+			assignment.Inputs[i] = v
+		}
+		for i, v := range out {
+			assignment.Outputs[i] = v
+		}
+		if err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()); err != nil {
+			t.Fatalf("t=%d: %v", n+1, err)
+		}
+
+		// a different first output is rejected
+		assignment.Outputs[0] = new(big.Int).Add(out[0], big.NewInt(1))
+		if err := test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()); err == nil {
+			t.Fatalf("t=%d: wrong output accepted", n+1)
+		}
+	}
+}
+
+// the unoptimized permutation with the reference tables, full rounds on the whole state and partial rounds on
+// the first element, each followed by the MDS matrix
+func referenceHash(inputs []*big.Int) *big.Int {
+	nRoundsPC := [16]int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68}
+	t := len(inputs) + 1
+	nRoundsF := 8
+	nRoundsP := nRoundsPC[t-2]
+	c := referenceC()[t-2]
+	m := referenceM()[t-2]
+
+	state := make([]fr.Element, t)
+	for i, v := range inputs {
+		state[i+1].SetBigInt(v)
+	}
+	for r := 0; r < nRoundsF+nRoundsP; r++ {
+		for i := range state {
+			var k fr.Element
+			k.SetString(c[r*t+i])
+			state[i].Add(&state[i], &k)
+		}
+		if r < nRoundsF/2 || r >= nRoundsF/2+nRoundsP {
+			sigmaAll(state)
+		} else {
+			sigma(&state[0])
+		}
+
+		out := make([]fr.Element, t)
+		for i := range out {
+			for j := range state {
+				var k, mul fr.Element
+				k.SetString(m[i][j])
+				mul.Mul(&k, &state[j])
+				out[i].Add(&out[i], &mul)
+			}
+		}
+		state = out
+	}
+	return state[0].BigInt(new(big.Int))
+}
+
+func TestHashMatchesReference(t *testing.T) {
+	random := rand.New(rand.NewSource(4))
+	for n := 1; n <= len(referenceC()); n++ {
+		for _, inputs := range testInputs(n, random) {
+			h, err := Hash(inputs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := referenceHash(inputs); h.Cmp(expected) != 0 {
+				t.Fatalf("t=%d inputs %v: hash %s, reference %s", n+1, inputs, h, expected)
+			}
+		}
+	}
+}
+
+func TestHashInputs(t *testing.T) {
+	if _, err := Hash(); err == nil {
+		t.Error("no inputs accepted")
+	}
+	if _, err := Hash(make([]*big.Int, 17)...); err == nil {
+		t.Error("17 inputs accepted")
+	}
+	if _, err := Hash(fr.Modulus()); err == nil {
+		t.Error("input outside the field accepted")
+	}
+	if _, err := Hash(big.NewInt(-1)); err == nil {
+		t.Error("negative input accepted")
+	}
+	if _, err := HashEx([]*big.Int{big.NewInt(1)}, new(big.Int), 3); err == nil {
+		t.Error("more outputs than the state accepted")
+	}
+}
@@ -0,0 +1,101 @@
+package poseidon
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+)
+
+// PCT is a Poseidon ciphertext as stored by the contracts, the message is encrypted with the ECDH key
+// random·publicKey and AuthKey = random·base8 is published so the owner can derive it as privateKey·AuthKey
+type PCT struct {
+	Ciphertext []*big.Int
+	AuthKey    babyjub.Point
+	Nonce      *big.Int
+}
+
+// EncryptPCT encrypts the message for the public key with the given randomness and nonce, as CheckPCTReceiver
+// and CheckPCTAuditor expect them
+func EncryptPCT(publicKey *babyjub.Point, message []*big.Int, random, nonce *big.Int) (*PCT, error) {
+	if random == nil || random.Sign() <= 0 || random.Cmp(babyjub.BasePointOrder) >= 0 {
+		return nil, errors.New("randomness must be in [1, BasePointOrder)")
+	}
+	if err := babyjub.CheckPublicKey(publicKey); err != nil {
+		return nil, err
+	}
+
+	var key babyjub.Point
+	key.ScalarMul(publicKey, random)
+
+	ciphertext, err := Encrypt(message, &key, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &PCT{Ciphertext: ciphertext, AuthKey: babyjub.MulWithBasePoint(random), Nonce: new(big.Int).Set(nonce)}, nil
+}
+
+// NewPCT encrypts the message for the public key with randomness and nonce sampled from random (crypto/rand if nil),
+// the randomness is returned as the circuits need it as PCT.Random
+func NewPCT(publicKey *babyjub.Point, message []*big.Int, random io.Reader) (*PCT, *big.Int, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+
+	r, err := babyjub.GenerateKey(random)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// non zero nonce below 2^128 like the frontend
+	var nonce *big.Int
+	for nonce == nil || nonce.Sign() == 0 {
+		if nonce, err = rand.Int(random, two128); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	pct, err := EncryptPCT(publicKey, message, r, nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pct, r, nil
+}
+
+// Decrypt decrypts a message of the given length with the private key the PCT was encrypted for
+func (p *PCT) Decrypt(privateKey *big.Int, length int) ([]*big.Int, error) {
+	var key babyjub.Point
+	key.ScalarMul(&p.AuthKey, privateKey)
+	return Decrypt(p.Ciphertext, &key, p.Nonce, length)
+}
+
+// Values returns the ciphertext followed by the auth key and the nonce, the uint256[7] layout of the contracts
+// for a single element message
+func (p *PCT) Values() []*big.Int {
+	values := make([]*big.Int, 0, len(p.Ciphertext)+3)
+	for _, c := range p.Ciphertext {
+		values = append(values, new(big.Int).Set(c))
+	}
+	return append(values, p.AuthKey.BigX(), p.AuthKey.BigY(), new(big.Int).Set(p.Nonce))
+}
+
+// ParsePCT reads the layout of Values, the auth key must be on the curve
+func ParsePCT(values []*big.Int) (*PCT, error) {
+	n := len(values) - 3
+	if n < 4 || n%3 != 1 {
+		return nil, errors.New("a PCT holds 3k+1 ciphertext elements followed by the auth key and the nonce")
+	}
+
+	authKey, err := babyjub.NewPoint(values[n], values[n+1])
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := make([]*big.Int, n)
+	for i := range ciphertext {
+		ciphertext[i] = new(big.Int).Set(values[i])
+	}
+	return &PCT{Ciphertext: ciphertext, AuthKey: authKey, Nonce: new(big.Int).Set(values[n+2])}, nil
+}
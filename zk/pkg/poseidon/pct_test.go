@@ -0,0 +1,123 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/babyjub"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// decrypts a single element PCT with the shared key in the circuit
+type decryptSingleCircuit struct {
+	Key        [2]frontend.Variable
+	Nonce      frontend.Variable
+	Ciphertext [4]frontend.Variable
+	Message    frontend.Variable
+}
+
+func (c *decryptSingleCircuit) Define(api frontend.API) error {
+	out := PoseidonDecryptSingle(api, c.Key, c.Nonce, c.Ciphertext)
+	api.AssertIsEqual(out[0], c.Message)
+	return nil
+}
+
+// PCT of 1000 for the private key 1234, with its shared key
+func testPCT(t *testing.T) (*PCT, *big.Int, babyjub.Point) {
+	privateKey := big.NewInt(1234)
+	publicKey, err := babyjub.PublicKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pct, err := EncryptPCT(&publicKey, []*big.Int{big.NewInt(1000)}, big.NewInt(5678), big.NewInt(91011))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var key babyjub.Point
+	key.ScalarMul(&pct.AuthKey, privateKey)
+	return pct, privateKey, key
+}
+
+func decryptSingleAssignment(p *PCT, key babyjub.Point, message int64) *decryptSingleCircuit {
+	return &decryptSingleCircuit{
+		Key:        [2]frontend.Variable{key.BigX(), key.BigY()},
+		Nonce:      p.Nonce,
+		Ciphertext: [4]frontend.Variable{p.Ciphertext[0], p.Ciphertext[1], p.Ciphertext[2], p.Ciphertext[3]},
+		Message:    message,
+	}
+}
+
+func TestPoseidonDecryptSingle(t *testing.T) {
+	pct, _, key := testPCT(t)
+
+	if err := test.IsSolved(&decryptSingleCircuit{}, decryptSingleAssignment(pct, key, 1000), ecc.BN254.ScalarField()); err != nil {
+		t.Fatalf("native PCT: %v", err)
+	}
+	if err := test.IsSolved(&decryptSingleCircuit{}, decryptSingleAssignment(pct, key, 1001), ecc.BN254.ScalarField()); err == nil {
+		t.Fatal("decrypted to another message")
+	}
+
+	tampered := decryptSingleAssignment(pct, key, 1000)
+	tampered.Ciphertext[3] = new(big.Int).Add(pct.Ciphertext[3], big.NewInt(1))
+	if err := test.IsSolved(&decryptSingleCircuit{}, tampered, ecc.BN254.ScalarField()); err == nil {
+		t.Fatal("accepted a tampered auth tag")
+	}
+}
+
+func TestPCTDecrypt(t *testing.T) {
+	pct, privateKey, _ := testPCT(t)
+
+	message, err := pct.Decrypt(privateKey, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message[0].Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("decrypted %s, expected 1000", message[0])
+	}
+
+	parsed, err := ParsePCT(pct.Values())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message, err := parsed.Decrypt(privateKey, 1); err != nil || message[0].Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("parsed PCT decrypted %v, %v", message, err)
+	}
+}
+
+func TestPCTDecryptRejectsTampering(t *testing.T) {
+	pct, privateKey, _ := testPCT(t)
+	otherAuthKey := babyjub.MulWithBasePoint(big.NewInt(5679))
+
+	tests := []struct {
+		name       string
+		tamper     func(p *PCT)
+		privateKey *big.Int
+	}{
+		{name: "auth tag", tamper: func(p *PCT) { p.Ciphertext[3] = new(big.Int).Add(p.Ciphertext[3], big.NewInt(1)) }},
+		{name: "ciphertext", tamper: func(p *PCT) { p.Ciphertext[1] = new(big.Int).Add(p.Ciphertext[1], big.NewInt(1)) }},
+		{name: "nonce", tamper: func(p *PCT) { p.Nonce = new(big.Int).Add(p.Nonce, big.NewInt(1)) }},
+		{name: "auth key", tamper: func(p *PCT) { p.AuthKey = otherAuthKey }},
+		{name: "private key", privateKey: big.NewInt(1235)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered, err := ParsePCT(pct.Values())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.tamper != nil {
+				tt.tamper(tampered)
+			}
+			key := privateKey
+			if tt.privateKey != nil {
+				key = tt.privateKey
+			}
+			if _, err := tampered.Decrypt(key, 1); err == nil {
+				t.Fatal("decrypted the tampered PCT")
+			}
+		})
+	}
+}
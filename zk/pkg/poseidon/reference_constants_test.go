@@ -1,6 +1,11 @@
-package utils
+package poseidon
 
-func C() [][]string {
+// Round constants and MDS matrices of the unoptimized Poseidon permutation for t = 2..9, the tables of circomlib's
+// poseidon_old the optimized C, S, M and P tables are derived from. They were pkg/utils/poseidon_constants.go,
+// TestHashMatchesReference checks the optimized tables hash like them.
+
+// referenceC returns the round constants, t·(nRoundsF + nRoundsP) for the width t at index t-2
+func referenceC() [][]string {
 	return [][]string{
 		{
 			"4417881134626180770308697923359573201005643519861877412381846989312604493735",
@@ -3060,7 +3065,8 @@ func C() [][]string {
 	}
 }
 
-func M() [][][]string {
+// referenceM returns the t×t MDS matrix for the width t at index t-2
+func referenceM() [][][]string {
 	return [][][]string{
 		{
 			{
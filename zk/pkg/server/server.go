@@ -0,0 +1,349 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/EncryptedERC/pkg/hardhat"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+)
+
+type Config struct {
+	// TCP address to listen on (e.g. 127.0.0.1:8590), ignored if Socket is set
+	Addr string
+	// path of the unix socket to listen on
+	Socket string
+	// directory holding the <NAME>.r1cs and <NAME>.pk artifacts
+	Dir string
+	// circuits to serve, every circuit with artifacts in Dir if empty
+	Circuits []string
+	// maximum number of proofs generated at the same time
+	MaxConcurrent int
+	// accept artifacts without a manifest, extracted before manifests were introduced
+	AllowMissingManifest bool
+}
+
+type loadedCircuit struct {
+	ccs constraint.ConstraintSystem
+	pk  groth16.ProvingKey
+}
+
+// Server keeps the constraint systems and proving keys resident and proves on request
+type Server struct {
+	cfg      Config
+	mu       sync.RWMutex
+	circuits map[string]*loadedCircuit
+	ready    bool
+	loadErr  error
+	slots    chan struct{}
+}
+
+func New(cfg Config) *Server {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 1
+	}
+	return &Server{
+		cfg:      cfg,
+		circuits: make(map[string]*loadedCircuit),
+		slots:    make(chan struct{}, cfg.MaxConcurrent),
+	}
+}
+
+// returns the circuits to serve, either configured or discovered from the artifact directory
+func (s *Server) circuitNames() ([]string, error) {
+	if len(s.cfg.Circuits) > 0 {
+		names := make([]string, len(s.cfg.Circuits))
+		for i, name := range s.cfg.Circuits {
+			name = strings.ToUpper(strings.TrimSpace(name))
+			if _, ok := hardhat.Circuits[name]; !ok {
+				return nil, fmt.Errorf("unknown circuit %s", name)
+			}
+			names[i] = name
+		}
+		return names, nil
+	}
+
+	var names []string
+	for name := range hardhat.Circuits {
+		if fileExists(s.csPath(name)) && fileExists(s.pkPath(name)) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no circuit artifacts found in %s", s.cfg.Dir)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *Server) csPath(name string) string { return filepath.Join(s.cfg.Dir, name+".r1cs") }
+func (s *Server) pkPath(name string) string { return filepath.Join(s.cfg.Dir, name+".pk") }
+
+// Load reads the constraint system and proving key of every served circuit once
+func (s *Server) Load() error {
+	err := s.load()
+
+	s.mu.Lock()
+	s.ready = err == nil
+	s.loadErr = err
+	s.mu.Unlock()
+
+	return err
+}
+
+func (s *Server) load() error {
+	names, err := s.circuitNames()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		start := time.Now()
+
+		ccs, err := helpers.ReadCS(s.csPath(name))
+		if err != nil {
+			return fmt.Errorf("loading %s constraint system: %w", name, err)
+		}
+		pk, err := helpers.ReadPK(s.pkPath(name))
+		if err != nil {
+			return fmt.Errorf("loading %s proving key: %w", name, err)
+		}
+		if err := helpers.VerifyArtifacts(name, hardhat.Circuits[name].New(), ccs, pk, s.csPath(name), s.pkPath(name), s.cfg.AllowMissingManifest); err != nil {
+			return fmt.Errorf("loading %s: %w", name, err)
+		}
+
+		s.mu.Lock()
+		s.circuits[name] = &loadedCircuit{ccs: ccs, pk: pk}
+		s.mu.Unlock()
+
+		log.Printf("loaded %s in %s", name, time.Since(start))
+	}
+	return nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /readyz", s.handleReady)
+	mux.HandleFunc("POST /prove/{circuit}", s.handleProve)
+	mux.HandleFunc("POST /check/{circuit}", s.handleCheck)
+	return mux
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+}
+
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	loaded := make([]string, 0, len(s.circuits))
+	for name := range s.circuits {
+		loaded = append(loaded, name)
+	}
+	sort.Strings(loaded)
+
+	body := map[string]interface{}{"ready": s.ready, "circuits": loaded}
+	if s.loadErr != nil {
+		body["error"] = s.loadErr.Error()
+	}
+
+	status := http.StatusOK
+	if !s.ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, body)
+}
+
+func (s *Server) handleProve(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToUpper(r.PathValue("circuit"))
+	spec, ok := hardhat.Circuits[name]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": fmt.Sprintf("unknown circuit %s", name)})
+		return
+	}
+
+	s.mu.RLock()
+	circuit, loaded := s.circuits[name]
+	s.mu.RUnlock()
+	if !loaded {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"error": fmt.Sprintf("circuit %s is not loaded", name)})
+		return
+	}
+
+	var inputs hardhat.Inputs
+	if err := decodeInputs(w, r, &inputs); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	witness, err := inputs.Witness(spec.New())
+	if err != nil {
+		writeError(w, helpers.NewError(helpers.KindInput, "generating witness", err))
+		return
+	}
+
+	publicSignals, err := spec.PublicSignals(witness)
+	if err != nil {
+		writeError(w, helpers.NewError(helpers.KindInput, "extracting public signals", err))
+		return
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-r.Context().Done():
+		return
+	}
+
+	start := time.Now()
+	proof, err := groth16.Prove(circuit.ccs, circuit.pk, witness)
+	if err != nil {
+		writeError(w, helpers.NewError(helpers.KindConstraint, "proving", err))
+		return
+	}
+	log.Printf("proved %s in %s", name, time.Since(start))
+
+	a, b, c, err := utils.SetProof(proof)
+	if err != nil {
+		writeError(w, helpers.NewError(helpers.KindUnknown, "serializing proof", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"proof":         utils.FormatProof(&a, &b, &c),
+		"publicSignals": publicSignals,
+	})
+}
+
+// runs the checks of the circuit for the inputs without proving, the circuit does not need to be loaded
+func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToUpper(r.PathValue("circuit"))
+	if _, ok := hardhat.Circuits[name]; !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": fmt.Sprintf("unknown circuit %s", name)})
+		return
+	}
+
+	var inputs hardhat.Inputs
+	if err := decodeInputs(w, r, &inputs); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	report, err := hardhat.Check(name, inputs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// ListenAndServe starts accepting requests right away and loads the circuits in the background,
+// /readyz reports once every circuit is resident. It returns when the context is cancelled.
+func ListenAndServe(ctx context.Context, cfg Config) error {
+	s := New(cfg)
+
+	listener, err := s.listen()
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := s.Load(); err != nil {
+			log.Printf("loading circuits failed: %v", err)
+		}
+	}()
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", listener.Addr())
+		errCh <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+func (s *Server) listen() (net.Listener, error) {
+	if s.cfg.Socket != "" {
+		// remove a stale socket left behind by a previous run
+		if err := os.Remove(s.cfg.Socket); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return net.Listen("unix", s.cfg.Socket)
+	}
+	if s.cfg.Addr == "" {
+		return nil, errors.New("either an address or a unix socket is required")
+	}
+	return net.Listen("tcp", s.cfg.Addr)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("writing response: %v", err)
+	}
+}
+
+// largest request body, the named inputs of the largest circuit take a few kilobytes
+const maxBodySize = 1 << 20
+
+// decodes the inputs of the request body, a body larger than maxBodySize is refused
+func decodeInputs(w http.ResponseWriter, r *http.Request, inputs *hardhat.Inputs) error {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(inputs); err != nil {
+		return helpers.NewError(helpers.KindInput, "parsing inputs", err)
+	}
+	return nil
+}
+
+// maps the error kind to the response status
+func writeError(w http.ResponseWriter, err error) {
+	kind := helpers.KindOf(err)
+
+	status := http.StatusInternalServerError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case kind == helpers.KindInput:
+		status = http.StatusBadRequest
+	case kind == helpers.KindConstraint:
+		status = http.StatusUnprocessableEntity
+	}
+
+	writeJSON(w, status, map[string]interface{}{"error": err.Error(), "kind": kind.String()})
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
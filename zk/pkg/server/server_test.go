@@ -0,0 +1,243 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/builder"
+	"github.com/ava-labs/EncryptedERC/pkg/hardhat"
+	"github.com/ava-labs/EncryptedERC/pkg/helpers"
+	"github.com/ava-labs/EncryptedERC/pkg/utils"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestWriteErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		kind   string
+	}{
+		{helpers.NewError(helpers.KindInput, "parsing inputs", errors.New("bad")), http.StatusBadRequest, helpers.KindInput.String()},
+		{helpers.NewError(helpers.KindConstraint, "proving", errors.New("unsatisfied")), http.StatusUnprocessableEntity, helpers.KindConstraint.String()},
+		{helpers.NewError(helpers.KindArtifact, "loading", errors.New("missing")), http.StatusInternalServerError, helpers.KindArtifact.String()},
+		{helpers.NewError(helpers.KindIO, "reading", errors.New("denied")), http.StatusInternalServerError, helpers.KindIO.String()},
+		{errors.New("plain"), http.StatusInternalServerError, helpers.KindUnknown.String()},
+		{helpers.NewError(helpers.KindInput, "parsing inputs", &http.MaxBytesError{Limit: maxBodySize}), http.StatusRequestEntityTooLarge, helpers.KindInput.String()},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		writeError(rec, tt.err)
+
+		if rec.Code != tt.status {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.status, rec.Code)
+		}
+		var body struct {
+			Error string `json:"error"`
+			Kind  string `json:"kind"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Kind != tt.kind || body.Error != tt.err.Error() {
+			t.Errorf("%v: unexpected body %+v", tt.err, body)
+		}
+	}
+}
+
+// a well-formed body, only its size is refused
+var oversizedBody = `{"inputs": {"Sender.Address": "` + strings.Repeat("1", maxBodySize) + `"}}`
+
+func TestBodyTooLarge(t *testing.T) {
+	ts := httptest.NewServer(New(Config{Dir: t.TempDir()}).Handler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/check/REGISTER", "application/json", strings.NewReader(oversizedBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status 413, got %d", resp.StatusCode)
+	}
+}
+
+func TestNotReady(t *testing.T) {
+	s := New(Config{Dir: t.TempDir()})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodGet, "/healthz", http.StatusOK},
+		{http.MethodGet, "/readyz", http.StatusServiceUnavailable},
+		{http.MethodPost, "/prove/REGISTER", http.StatusServiceUnavailable},
+		{http.MethodPost, "/prove/UNKNOWN", http.StatusNotFound},
+		{http.MethodPost, "/check/UNKNOWN", http.StatusNotFound},
+		{http.MethodPost, "/check/REGISTER", http.StatusBadRequest},
+	}
+
+	// nothing to load from an empty directory
+	if err := s.Load(); err == nil {
+		t.Fatal("expected loading an empty directory to fail")
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader("{"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.status, resp.StatusCode)
+		}
+	}
+}
+
+func TestProveAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	spec := hardhat.Circuits["REGISTER"]
+
+	ccs, pk, vk, err := helpers.LoadCircuit(helpers.TestingParams{IsNew: true}, "REGISTER", spec.New)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := helpers.SaveArtifacts(ccs, pk, vk, dir, "REGISTER"); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(Config{Dir: dir})
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected ready, got %d", resp.StatusCode)
+	}
+
+	assignment, err := builder.Registration(big.NewInt(1234), big.NewInt(0xbeef), big.NewInt(43114))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := namedInputs(t, assignment)
+	body, err := json.Marshal(map[string]interface{}{"inputs": fields})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = http.Post(ts.URL+"/prove/register", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var out hardhat.VerifyInputs
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := builder.PublicSignals(assignment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(out.PublicSignals) != fmt.Sprint(expected) {
+		t.Fatalf("expected public signals %v, got %v", expected, out.PublicSignals)
+	}
+
+	valid, err := hardhat.VerifyProof(vk, out.Proof, out.PublicSignals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid {
+		t.Fatal("the proof of the server does not verify")
+	}
+
+	// the proof is bound to the public signals
+	out.PublicSignals[2] = "1"
+	if valid, err := hardhat.VerifyProof(vk, out.Proof, out.PublicSignals); err != nil || valid {
+		t.Fatalf("expected the proof not to verify with another address, got %v, %v", valid, err)
+	}
+
+	// inputs that do not satisfy the circuit
+	fields["Sender.RegistrationHash"] = "1"
+	body, err = json.Marshal(map[string]interface{}{"inputs": fields})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = http.Post(ts.URL+"/prove/REGISTER", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 for an unsatisfied circuit, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(ts.URL+"/prove/REGISTER", "application/json", strings.NewReader(oversizedBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413 for an oversized body, got %d", resp.StatusCode)
+	}
+}
+
+// returns the values of the assignment keyed by circuit field path
+func namedInputs(t *testing.T, assignment frontend.Circuit) map[string]string {
+	t.Helper()
+
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := w.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vector := w.Vector().(fr.Vector)
+	nbPublic := len(public.Vector().(fr.Vector))
+
+	fields, err := utils.CircuitFields(assignment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the witness holds the public leaves first then the secret ones, each in walk order
+	named := make(map[string]string, len(fields))
+	iPublic, iSecret := 0, nbPublic
+	for _, field := range fields {
+		if field.Public {
+			named[field.Name] = vector[iPublic].String()
+			iPublic++
+		} else {
+			named[field.Name] = vector[iSecret].String()
+			iSecret++
+		}
+	}
+	return named
+}
@@ -0,0 +1,152 @@
+package snarkjs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+)
+
+// VerificationKey is the verification_key.json format of snarkjs for groth16 over bn128
+type VerificationKey struct {
+	Protocol      string       `json:"protocol"`
+	Curve         string       `json:"curve"`
+	NPublic       int          `json:"nPublic"`
+	VkAlpha1      []string     `json:"vk_alpha_1"`
+	VkBeta2       [][]string   `json:"vk_beta_2"`
+	VkGamma2      [][]string   `json:"vk_gamma_2"`
+	VkDelta2      [][]string   `json:"vk_delta_2"`
+	VkAlphaBeta12 [][][]string `json:"vk_alphabeta_12"`
+	IC            [][]string   `json:"IC"`
+}
+
+// Proof is the proof.json format of snarkjs for groth16 over bn128
+type Proof struct {
+	PiA      []string   `json:"pi_a"`
+	PiB      [][]string `json:"pi_b"`
+	PiC      []string   `json:"pi_c"`
+	Protocol string     `json:"protocol"`
+	Curve    string     `json:"curve"`
+}
+
+// ExportVerifyingKey converts the gnark verifying key to the snarkjs format
+func ExportVerifyingKey(vk groth16.VerifyingKey) (*VerificationKey, error) {
+	key, ok := vk.(*groth16_bn254.VerifyingKey)
+	if !ok {
+		return nil, errors.New("verifying key is not defined over bn254")
+	}
+	// snarkjs has no notion of gnark's Pedersen commitments
+	if len(key.CommitmentKeys) != 0 {
+		return nil, errors.New("verifying keys with commitments can not be exported to snarkjs")
+	}
+
+	alphaBeta, err := curve.Pair([]curve.G1Affine{key.G1.Alpha}, []curve.G2Affine{key.G2.Beta})
+	if err != nil {
+		return nil, err
+	}
+
+	ic := make([][]string, len(key.G1.K))
+	for i := range key.G1.K {
+		ic[i] = g1(&key.G1.K[i])
+	}
+
+	return &VerificationKey{
+		Protocol:      "groth16",
+		Curve:         "bn128",
+		NPublic:       len(key.G1.K) - 1,
+		VkAlpha1:      g1(&key.G1.Alpha),
+		VkBeta2:       g2(&key.G2.Beta),
+		VkGamma2:      g2(&key.G2.Gamma),
+		VkDelta2:      g2(&key.G2.Delta),
+		VkAlphaBeta12: gt(&alphaBeta),
+		IC:            ic,
+	}, nil
+}
+
+// ExportProof converts the gnark proof to the snarkjs format
+func ExportProof(proof groth16.Proof) (*Proof, error) {
+	p, ok := proof.(*groth16_bn254.Proof)
+	if !ok {
+		return nil, errors.New("proof is not defined over bn254")
+	}
+	if len(p.Commitments) != 0 {
+		return nil, errors.New("proofs with commitments can not be exported to snarkjs")
+	}
+
+	return &Proof{
+		PiA:      g1(&p.Ar),
+		PiB:      g2(&p.Bs),
+		PiC:      g1(&p.Krs),
+		Protocol: "groth16",
+		Curve:    "bn128",
+	}, nil
+}
+
+// WriteVerifyingKey writes the verifying key as snarkjs verification_key.json
+func WriteVerifyingKey(vk groth16.VerifyingKey, filename string) error {
+	key, err := ExportVerifyingKey(vk)
+	if err != nil {
+		return err
+	}
+	return WriteJSON(filename, key)
+}
+
+// WriteProof writes the proof and the public signals as snarkjs proof.json and public.json
+func WriteProof(proof groth16.Proof, publicSignals []string, proofFile, publicFile string) error {
+	p, err := ExportProof(proof)
+	if err != nil {
+		return err
+	}
+	if err := WriteJSON(proofFile, p); err != nil {
+		return err
+	}
+	return WriteJSON(publicFile, publicSignals)
+}
+
+// WriteJSON writes v indented the way snarkjs writes its json files
+func WriteJSON(filename string, v interface{}) error {
+	out, err := json.MarshalIndent(v, "", " ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, out, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", filename, err)
+	}
+	return nil
+}
+
+// points are written in projective form with z = 1, as snarkjs does
+func g1(p *curve.G1Affine) []string {
+	return []string{fpString(&p.X), fpString(&p.Y), "1"}
+}
+
+func g2(p *curve.G2Affine) [][]string {
+	return [][]string{
+		{fpString(&p.X.A0), fpString(&p.X.A1)},
+		{fpString(&p.Y.A0), fpString(&p.Y.A1)},
+		{"1", "0"},
+	}
+}
+
+func gt(e *curve.GT) [][][]string {
+	e6 := func(c *[3][2]*fp.Element) [][]string {
+		out := make([][]string, 3)
+		for i := range c {
+			out[i] = []string{fpString(c[i][0]), fpString(c[i][1])}
+		}
+		return out
+	}
+	return [][][]string{
+		e6(&[3][2]*fp.Element{{&e.C0.B0.A0, &e.C0.B0.A1}, {&e.C0.B1.A0, &e.C0.B1.A1}, {&e.C0.B2.A0, &e.C0.B2.A1}}),
+		e6(&[3][2]*fp.Element{{&e.C1.B0.A0, &e.C1.B0.A1}, {&e.C1.B1.A0, &e.C1.B1.A1}, {&e.C1.B2.A0, &e.C1.B2.A1}}),
+	}
+}
+
+func fpString(e *fp.Element) string {
+	return e.String()
+}
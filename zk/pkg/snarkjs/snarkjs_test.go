@@ -0,0 +1,262 @@
+package snarkjs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// testdata/registration_verification_key.json is the verification key snarkjs exported for the circom registration
+// circuit (circom/build/registration)
+const snarkjsKey = "registration_verification_key.json"
+
+func readKey(t *testing.T, name string) *VerificationKey {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var key VerificationKey
+	if err := json.Unmarshal(raw, &key); err != nil {
+		t.Fatal(err)
+	}
+	return &key
+}
+
+func parseFp(t *testing.T, s string) fp.Element {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok || v.Cmp(fp.Modulus()) >= 0 {
+		t.Fatalf("%q is not a base field element", s)
+	}
+	var e fp.Element
+	e.SetBigInt(v)
+	return e
+}
+
+// reads a snarkjs G1 point, projective with z = 1
+func parseG1(t *testing.T, p []string) curve.G1Affine {
+	t.Helper()
+	if len(p) != 3 || p[2] != "1" {
+		t.Fatalf("expected an affine G1 point, got %v", p)
+	}
+	point := curve.G1Affine{X: parseFp(t, p[0]), Y: parseFp(t, p[1])}
+	if !point.IsOnCurve() {
+		t.Fatalf("%v is not on G1", p)
+	}
+	return point
+}
+
+// reads a snarkjs G2 point, every coordinate is [a0, a1] for a0 + a1·u
+func parseG2(t *testing.T, p [][]string) curve.G2Affine {
+	t.Helper()
+	if len(p) != 3 || len(p[2]) != 2 || p[2][0] != "1" || p[2][1] != "0" {
+		t.Fatalf("expected an affine G2 point, got %v", p)
+	}
+	var point curve.G2Affine
+	point.X.A0, point.X.A1 = parseFp(t, p[0][0]), parseFp(t, p[0][1])
+	point.Y.A0, point.Y.A1 = parseFp(t, p[1][0]), parseFp(t, p[1][1])
+	if !point.IsOnCurve() || !point.IsInSubGroup() {
+		t.Fatalf("%v is not in G2", p)
+	}
+	return point
+}
+
+// reads the snarkjs GT element, the inverse of gt
+func parseGT(t *testing.T, e [][][]string) curve.GT {
+	t.Helper()
+	var out curve.GT
+	coefficients := [2][3]*[2]*fp.Element{
+		{{&out.C0.B0.A0, &out.C0.B0.A1}, {&out.C0.B1.A0, &out.C0.B1.A1}, {&out.C0.B2.A0, &out.C0.B2.A1}},
+		{{&out.C1.B0.A0, &out.C1.B0.A1}, {&out.C1.B1.A0, &out.C1.B1.A1}, {&out.C1.B2.A0, &out.C1.B2.A1}},
+	}
+	if len(e) != 2 {
+		t.Fatalf("expected 2 Fp6 coefficients, got %d", len(e))
+	}
+	for i := range coefficients {
+		if len(e[i]) != 3 {
+			t.Fatalf("expected 3 Fp2 coefficients, got %d", len(e[i]))
+		}
+		for j := range coefficients[i] {
+			if len(e[i][j]) != 2 {
+				t.Fatalf("expected 2 Fp coefficients, got %d", len(e[i][j]))
+			}
+			*coefficients[i][j][0] = parseFp(t, e[i][j][0])
+			*coefficients[i][j][1] = parseFp(t, e[i][j][1])
+		}
+	}
+	return out
+}
+
+// the gnark verifying key holding the points of the snarkjs key
+func gnarkKey(t *testing.T, key *VerificationKey) *groth16_bn254.VerifyingKey {
+	t.Helper()
+	var vk groth16_bn254.VerifyingKey
+	vk.G1.Alpha = parseG1(t, key.VkAlpha1)
+	vk.G2.Beta = parseG2(t, key.VkBeta2)
+	vk.G2.Gamma = parseG2(t, key.VkGamma2)
+	vk.G2.Delta = parseG2(t, key.VkDelta2)
+	for _, p := range key.IC {
+		vk.G1.K = append(vk.G1.K, parseG1(t, p))
+	}
+	return &vk
+}
+
+// the pairing check of snarkjs groth16.verify, against the vk_alphabeta_12 of the exported key:
+// e(A, B) = alphabeta · e(IC[0] + Σ public[i]·IC[i+1], gamma) · e(C, delta)
+func snarkjsVerify(t *testing.T, key *VerificationKey, proof *Proof, publicSignals []string) bool {
+	t.Helper()
+	if len(publicSignals) != key.NPublic || len(key.IC) != key.NPublic+1 {
+		t.Fatalf("expected %d public signals, got %d", key.NPublic, len(publicSignals))
+	}
+
+	var vkX, term curve.G1Affine
+	vkX = parseG1(t, key.IC[0])
+	for i, s := range publicSignals {
+		v, ok := new(big.Int).SetString(s, 10)
+		if !ok || v.Cmp(fr.Modulus()) >= 0 {
+			t.Fatalf("public signal %q is not a scalar field element", s)
+		}
+		ic := parseG1(t, key.IC[i+1])
+		term.ScalarMultiplication(&ic, v)
+		vkX.Add(&vkX, &term)
+	}
+
+	a, b, c := parseG1(t, proof.PiA), parseG2(t, proof.PiB), parseG1(t, proof.PiC)
+	var negX, negC curve.G1Affine
+	negX.Neg(&vkX)
+	negC.Neg(&c)
+
+	lhs, err := curve.Pair([]curve.G1Affine{a, negX, negC}, []curve.G2Affine{b, parseG2(t, key.VkGamma2), parseG2(t, key.VkDelta2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alphaBeta := parseGT(t, key.VkAlphaBeta12)
+	return lhs.Equal(&alphaBeta)
+}
+
+func TestAlphaBetaMatchesSnarkjs(t *testing.T) {
+	key := readKey(t, snarkjsKey)
+
+	alphaBeta, err := curve.Pair([]curve.G1Affine{parseG1(t, key.VkAlpha1)}, []curve.G2Affine{parseG2(t, key.VkBeta2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := parseGT(t, key.VkAlphaBeta12)
+	if !alphaBeta.Equal(&expected) {
+		t.Fatal("e(alpha, beta) is not the vk_alphabeta_12 of snarkjs")
+	}
+	if fmt.Sprint(gt(&alphaBeta)) != fmt.Sprint(key.VkAlphaBeta12) {
+		t.Fatal("vk_alphabeta_12 is not serialized in the snarkjs order")
+	}
+}
+
+// the key exported from the points of the snarkjs key is the snarkjs file, byte for byte
+func TestExportVerifyingKeyMatchesSnarkjs(t *testing.T) {
+	expected, err := os.ReadFile(filepath.Join("testdata", snarkjsKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exported, err := ExportVerifyingKey(gnarkKey(t, readKey(t, snarkjsKey)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), snarkjsKey)
+	if err := WriteJSON(path, exported); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, bytes.TrimSpace(expected)) {
+		t.Fatalf("exported key differs from the snarkjs key:\n%s", out)
+	}
+}
+
+type mulCircuit struct {
+	X, Y frontend.Variable `gnark:",public"`
+	A, B frontend.Variable
+}
+
+func (c *mulCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.A, c.B), api.Add(c.X, api.Mul(3, c.Y)))
+	return nil
+}
+
+// a gnark proof exported with its key verifies with the snarkjs verification equation
+func TestExportProofVerifies(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &mulCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 6·7 = 30 + 3·4
+	w, err := frontend.NewWitness(&mulCircuit{X: 30, Y: 4, A: 6, B: 7}, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := groth16.Prove(ccs, pk, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	keyFile, proofFile, publicFile := filepath.Join(dir, "verification_key.json"), filepath.Join(dir, "proof.json"), filepath.Join(dir, "public.json")
+	if err := WriteVerifyingKey(vk, keyFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteProof(proof, []string{"30", "4"}, proofFile, publicFile); err != nil {
+		t.Fatal(err)
+	}
+
+	var key VerificationKey
+	var p Proof
+	var publicSignals []string
+	for file, v := range map[string]interface{}{keyFile: &key, proofFile: &p, publicFile: &publicSignals} {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(raw, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if key.Protocol != "groth16" || key.Curve != "bn128" || key.NPublic != 2 || p.Protocol != "groth16" || p.Curve != "bn128" {
+		t.Fatalf("unexpected headers %s/%s/%d, %s/%s", key.Protocol, key.Curve, key.NPublic, p.Protocol, p.Curve)
+	}
+
+	alphaBeta, err := curve.Pair([]curve.G1Affine{parseG1(t, key.VkAlpha1)}, []curve.G2Affine{parseG2(t, key.VkBeta2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := parseGT(t, key.VkAlphaBeta12); !alphaBeta.Equal(&expected) {
+		t.Fatal("vk_alphabeta_12 is not e(alpha, beta)")
+	}
+
+	if !snarkjsVerify(t, &key, &p, publicSignals) {
+		t.Fatal("the exported proof does not verify with the snarkjs equation")
+	}
+	if snarkjsVerify(t, &key, &p, []string{"31", "4"}) {
+		t.Fatal("the exported proof verifies with other public signals")
+	}
+}
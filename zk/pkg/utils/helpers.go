@@ -3,13 +3,18 @@ package utils
 import (
 	"bytes"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"math/big"
 	"os"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
 	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
 	"github.com/iden3/go-iden3-crypto/utils"
 )
 
@@ -46,7 +51,10 @@ func Reduce[T any](s []T, f func(int, T, T) T, initValue T) T {
 }
 
 func GenerateWitness(publicIns, privateIns []string) (witness.Witness, error) {
-	ww, _ := witness.New(ecc.BN254.ScalarField())
+	ww, err := witness.New(ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
 	nbTotal := len(publicIns) + len(privateIns)
 	values := make(chan any, nbTotal)
 	go func() {
@@ -59,28 +67,38 @@ func GenerateWitness(publicIns, privateIns []string) (witness.Witness, error) {
 		close(values)
 	}()
 
-	err := ww.Fill(len(publicIns), len(privateIns), values)
+	err = ww.Fill(len(publicIns), len(privateIns), values)
 	if err != nil {
 		return nil, err
 	}
 	return ww, nil
 }
 
-// general helper function for writing the proof
-func WriteProof(output string, a *[2]string, b *[2][2]string, c *[2]string) {
-	proof := map[string]interface{}{
-		"proof": []string{a[0], a[1], b[0][0], b[0][1], b[1][0], b[1][1], c[0], c[1]},
+// flattens the proof points in the order the verifier contracts expect
+func FormatProof(a *[2]string, b *[2][2]string, c *[2]string) []string {
+	return []string{a[0], a[1], b[0][0], b[0][1], b[1][0], b[1][1], c[0], c[1]}
+}
+
+// general helper function for writing the proof together with its public signals
+func WriteProof(output string, a *[2]string, b *[2][2]string, c *[2]string, publicSignals []string) error {
+	return WriteProofWith(output, a, b, c, publicSignals, nil)
+}
+
+// writes the proof like WriteProof with additional top level fields, e.g. the balancePCT a transfer submits with it
+func WriteProofWith(output string, a *[2]string, b *[2][2]string, c *[2]string, publicSignals []string, extra map[string]interface{}) error {
+	proof := map[string]interface{}{}
+	for k, v := range extra {
+		proof[k] = v
 	}
+	proof["proof"] = FormatProof(a, b, c)
+	proof["publicSignals"] = publicSignals
 
 	proofJSON, err := json.Marshal(proof)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	err = os.WriteFile(output, proofJSON, 0644)
-	if err != nil {
-		log.Fatal(err)
-	}
+	return os.WriteFile(output, proofJSON, 0644)
 }
 
 // setProof function fills 'a', 'b', 'c' and public inputs for the generated proof
@@ -99,14 +117,117 @@ func setProofABC(proofBytes []byte, a *[2]string, b *[2][2]string, c *[2]string)
 
 // setProof function fills 'a', 'b', 'c' and public inputs for the generated proof
 // and writes the proof to the output file
-func SetProof(proof groth16.Proof) (a [2]string, b [2][2]string, c [2]string) {
+func SetProof(proof groth16.Proof) (a [2]string, b [2][2]string, c [2]string, err error) {
 	var buf bytes.Buffer
-	_, err := proof.WriteRawTo(&buf)
+	_, err = proof.WriteRawTo(&buf)
 	if err != nil {
-		panic(err)
+		return a, b, c, err
 	}
 	proofBytes := buf.Bytes()
 
 	setProofABC(proofBytes, &a, &b, &c)
-	return a, b, c
+	return a, b, c, nil
+}
+
+// ParseProof rebuilds the groth16 proof from the flattened points written by WriteProof
+func ParseProof(points []string) (groth16.Proof, error) {
+	if len(points) != 8 {
+		return nil, fmt.Errorf("proof must have 8 elements, got %d", len(points))
+	}
+
+	values := make([]fp.Element, len(points))
+	for i, p := range points {
+		v, ok := new(big.Int).SetString(p, 10)
+		if !ok {
+			return nil, fmt.Errorf("proof element %d is not a valid integer: %q", i, p)
+		}
+		if v.Sign() < 0 || v.Cmp(fp.Modulus()) >= 0 {
+			return nil, fmt.Errorf("proof element %d is not in the base field", i)
+		}
+		values[i].SetBigInt(v)
+	}
+
+	// the G2 coordinates are written as (A1, A0) pairs, see setProofABC
+	proof := &groth16_bn254.Proof{}
+	proof.Ar.X, proof.Ar.Y = values[0], values[1]
+	proof.Bs.X.A1, proof.Bs.X.A0 = values[2], values[3]
+	proof.Bs.Y.A1, proof.Bs.Y.A0 = values[4], values[5]
+	proof.Krs.X, proof.Krs.Y = values[6], values[7]
+
+	if !proof.Ar.IsInSubGroup() || !proof.Bs.IsInSubGroup() || !proof.Krs.IsInSubGroup() {
+		return nil, errors.New("proof points are not in the correct subgroup")
+	}
+
+	return proof, nil
+}
+
+// PublicSignals returns the public part of the witness as decimal strings in the order of the verifier contract,
+// see PublicOrder
+func PublicSignals(circuit frontend.Circuit, w witness.Witness) ([]string, error) {
+	public, err := w.Public()
+	if err != nil {
+		return nil, err
+	}
+
+	vector, ok := public.Vector().(fr.Vector)
+	if !ok {
+		return nil, errors.New("witness is not defined over the BN254 scalar field")
+	}
+
+	order, err := PublicOrder(circuit)
+	if err != nil {
+		return nil, err
+	}
+	if len(order) != len(vector) {
+		return nil, fmt.Errorf("expected %d public inputs, got %d", len(order), len(vector))
+	}
+
+	signals := make([]string, len(vector))
+	for i, j := range order {
+		signals[i] = vector[j].String()
+	}
+	return signals, nil
+}
+
+// OrderVerifyingKey reorders the public input points of a verifying key fresh out of the setup to the order
+// of PublicSignals, so the key verifies the signals as the contract passes them
+func OrderVerifyingKey(circuit frontend.Circuit, vk groth16.VerifyingKey) error {
+	key, ok := vk.(*groth16_bn254.VerifyingKey)
+	if !ok {
+		return errors.New("verifying key is not defined over bn254")
+	}
+
+	order, err := PublicOrder(circuit)
+	if err != nil {
+		return err
+	}
+	// K[0] is the constant one wire
+	if len(key.G1.K) != len(order)+1 {
+		return fmt.Errorf("verifying key is for %d public inputs, the circuit has %d", len(key.G1.K)-1, len(order))
+	}
+
+	k := append(key.G1.K[:0:0], key.G1.K...)
+	for i, j := range order {
+		key.G1.K[i+1] = k[j+1]
+	}
+	return nil
+}
+
+// PublicWitness builds the public part of the witness from the public signals
+func PublicWitness(publicSignals []string) (witness.Witness, error) {
+	ww, err := witness.New(ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(chan any, len(publicSignals))
+	for _, s := range publicSignals {
+		values <- s
+	}
+	close(values)
+
+	if err := ww.Fill(len(publicSignals), 0, values); err != nil {
+		return nil, err
+	}
+	return ww, nil
 }
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+type orderedCircuit struct {
+	A   frontend.Variable    `gnark:",public"`
+	B   [2]frontend.Variable `gnark:",public"`
+	C   frontend.Variable    `gnark:",public"`
+	Sum frontend.Variable
+}
+
+func (circuit *orderedCircuit) ContractOrder() []string { return []string{"C", "B", "A"} }
+
+func (circuit *orderedCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Add(circuit.A, api.Mul(circuit.B[0], 2), api.Mul(circuit.B[1], 3), api.Mul(circuit.C, 4)), circuit.Sum)
+	return nil
+}
+
+func TestPublicOrder(t *testing.T) {
+	order, err := PublicOrder(&orderedCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []int{3, 1, 2, 0}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("order %v, expected %v", order, expected)
+		}
+	}
+}
+
+// the reordered verifying key verifies the reordered signals, and only them
+func TestOrderVerifyingKey(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &orderedCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := OrderVerifyingKey(&orderedCircuit{}, vk); err != nil {
+		t.Fatal(err)
+	}
+
+	assignment := &orderedCircuit{A: 1, B: [2]frontend.Variable{2, 3}, C: 4, Sum: 30}
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := groth16.Prove(ccs, pk, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signals, err := PublicSignals(&orderedCircuit{}, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"4", "2", "3", "1"}; len(signals) != len(expected) || signals[0] != expected[0] || signals[3] != expected[3] {
+		t.Fatalf("signals %v, expected %v", signals, expected)
+	}
+
+	public, err := PublicWitness(signals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := groth16.Verify(proof, vk, public); err != nil {
+		t.Fatalf("ordered signals: %v", err)
+	}
+
+	// the witness order no longer verifies
+	public, err = PublicWitness([]string{"1", "2", "3", "4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := groth16.Verify(proof, vk, public); err == nil {
+		t.Fatal("witness order verified against the reordered key")
+	}
+}
@@ -0,0 +1,225 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/schema"
+)
+
+var tVariable = reflect.ValueOf(struct{ A frontend.Variable }{}).FieldByName("A").Type()
+
+// CircuitField describes a single leaf of a circuit, e.g. Sender.PublicKey.P.X
+type CircuitField struct {
+	Name   string
+	Public bool
+}
+
+// returns the leaves of the circuit in the order gnark assigns them to the witness
+func CircuitFields(circuit frontend.Circuit) ([]CircuitField, error) {
+	var fields []CircuitField
+	_, err := schema.Walk(circuit, tVariable, func(leaf schema.LeafInfo, _ reflect.Value) error {
+		fields = append(fields, CircuitField{Name: fieldPath(leaf.FullName()), Public: leaf.Visibility == schema.Public})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// ContractOrdered is implemented by the circuits whose verifier contract reads the public signals in another
+// order than gnark assigns them, it lists the public fields (or the structs holding them) in the contract order
+type ContractOrdered interface {
+	ContractOrder() []string
+}
+
+// PublicOrder returns for every public signal of the verifier contract the index of the public leaf of the witness it reads,
+// the identity unless the circuit is ContractOrdered. The leaves of a listed struct keep their walk order.
+func PublicOrder(circuit frontend.Circuit) ([]int, error) {
+	fields, err := CircuitFields(circuit)
+	if err != nil {
+		return nil, err
+	}
+
+	var public []string
+	for _, field := range fields {
+		if field.Public {
+			public = append(public, field.Name)
+		}
+	}
+
+	order := make([]int, len(public))
+	for i := range order {
+		order[i] = i
+	}
+	ordered, ok := circuit.(ContractOrdered)
+	if !ok {
+		return order, nil
+	}
+
+	rank := make([]int, len(public))
+	for i, name := range public {
+		rank[i] = -1
+		for r, prefix := range ordered.ContractOrder() {
+			if name == prefix || strings.HasPrefix(name, prefix+".") || strings.HasPrefix(name, prefix+"[") {
+				rank[i] = r
+				break
+			}
+		}
+		if rank[i] < 0 {
+			return nil, fmt.Errorf("public field %s is missing from the contract order", name)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool { return rank[order[a]] < rank[order[b]] })
+	return order, nil
+}
+
+// GenerateWitnessFromFields fills the given circuit assignment from values keyed by
+// circuit field path (e.g. Auditor.PCT.Ciphertext[2]) and returns the full witness.
+// Every leaf of the circuit must be present exactly once, values are decimal or 0x-prefixed
+// hexadecimal strings (JSON numbers are accepted as well) and must be reduced field elements.
+func GenerateWitnessFromFields(assignment frontend.Circuit, fields map[string]json.RawMessage) (witness.Witness, error) {
+	modulus := ecc.BN254.ScalarField()
+
+	seen := make(map[string]bool, len(fields))
+	var missing, malformed []string
+
+	_, err := schema.Walk(assignment, tVariable, func(leaf schema.LeafInfo, tValue reflect.Value) error {
+		name := fieldPath(leaf.FullName())
+		raw, ok := fields[name]
+		if !ok {
+			missing = append(missing, name)
+			return nil
+		}
+		seen[name] = true
+
+		value, err := ParseFieldValue(raw, modulus)
+		if err != nil {
+			malformed = append(malformed, fmt.Sprintf("%s: %v", name, err))
+			return nil
+		}
+		if !tValue.CanSet() {
+			return fmt.Errorf("field %s can not be assigned", name)
+		}
+		tValue.Set(reflect.ValueOf(frontend.Variable(value)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var extra []string
+	for name := range fields {
+		if !seen[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing fields: "+strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		problems = append(problems, "unknown fields: "+strings.Join(extra, ", "))
+	}
+	if len(malformed) > 0 {
+		problems = append(problems, "malformed fields: "+strings.Join(malformed, "; "))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid circuit inputs: %s", strings.Join(problems, "; "))
+	}
+
+	return frontend.NewWitness(assignment, modulus)
+}
+
+// AssignWitness fills the circuit assignment from the full witness, it is the inverse of frontend.NewWitness
+func AssignWitness(assignment frontend.Circuit, w witness.Witness) error {
+	vector, ok := w.Vector().(fr.Vector)
+	if !ok {
+		return errors.New("witness is not defined over bn254")
+	}
+	public, err := w.Public()
+	if err != nil {
+		return err
+	}
+	nbPublic := len(public.Vector().(fr.Vector))
+
+	fields, err := CircuitFields(assignment)
+	if err != nil {
+		return err
+	}
+	if len(fields) != len(vector) {
+		return fmt.Errorf("expected %d inputs, got %d", len(fields), len(vector))
+	}
+
+	// the witness holds the public leaves first then the secret ones, each in walk order
+	iPublic, iSecret := 0, nbPublic
+	_, err = schema.Walk(assignment, tVariable, func(leaf schema.LeafInfo, tValue reflect.Value) error {
+		var value big.Int
+		if leaf.Visibility == schema.Public {
+			vector[iPublic].BigInt(&value)
+			iPublic++
+		} else {
+			vector[iSecret].BigInt(&value)
+			iSecret++
+		}
+		tValue.Set(reflect.ValueOf(frontend.Variable(&value)))
+		return nil
+	})
+	return err
+}
+
+// converts gnark's leaf name (Auditor_PCT_Ciphertext_2) to the field path (Auditor.PCT.Ciphertext[2])
+func fieldPath(leafName string) string {
+	var sb strings.Builder
+	for i, part := range strings.Split(leafName, "_") {
+		if _, err := strconv.Atoi(part); err == nil {
+			sb.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(part)
+	}
+	return sb.String()
+}
+
+// ParseFieldValue parses a JSON decimal or 0x prefixed hexadecimal string, or a JSON number, into a field element
+func ParseFieldValue(raw json.RawMessage, modulus *big.Int) (*big.Int, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, fmt.Errorf("expected a string or number, got %s", string(raw))
+		}
+		s = n.String()
+	}
+
+	s = strings.TrimSpace(s)
+	value, ok := new(big.Int), false
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		value, ok = value.SetString(s[2:], 16)
+	} else {
+		value, ok = value.SetString(s, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%q is not a valid integer", s)
+	}
+	if value.Sign() < 0 || value.Cmp(modulus) >= 0 {
+		return nil, fmt.Errorf("%s is not in the scalar field", s)
+	}
+	return value, nil
+}
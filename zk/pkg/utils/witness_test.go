@@ -0,0 +1,250 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/EncryptedERC/pkg/circuits"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+)
+
+// the circuits with one deeply nested leaf each, read back from the assignment to check the field path
+var witnessCircuits = []struct {
+	name string
+	new  func() frontend.Circuit
+	leaf string
+	get  func(c frontend.Circuit) frontend.Variable
+}{
+	{
+		name: "Registration",
+		new:  func() frontend.Circuit { return &circuits.RegistrationCircuit{} },
+		leaf: "Sender.PublicKey.P.Y",
+		get: func(c frontend.Circuit) frontend.Variable {
+			return c.(*circuits.RegistrationCircuit).Sender.PublicKey.P.Y
+		},
+	},
+	{
+		name: "Mint",
+		new:  func() frontend.Circuit { return &circuits.MintCircuit{} },
+		leaf: "Receiver.ValueRandom.R",
+		get:  func(c frontend.Circuit) frontend.Variable { return c.(*circuits.MintCircuit).Receiver.ValueRandom.R },
+	},
+	{
+		name: "Transfer",
+		new:  func() frontend.Circuit { return &circuits.TransferCircuit{} },
+		leaf: "Auditor.PCT.Ciphertext[2]",
+		get: func(c frontend.Circuit) frontend.Variable {
+			return c.(*circuits.TransferCircuit).Auditor.PCT.Ciphertext[2]
+		},
+	},
+	{
+		name: "Withdraw",
+		new:  func() frontend.Circuit { return &circuits.WithdrawCircuit{} },
+		leaf: "Sender.BalanceEGCT.C2.X",
+		get: func(c frontend.Circuit) frontend.Variable {
+			return c.(*circuits.WithdrawCircuit).Sender.BalanceEGCT.C2.X
+		},
+	},
+	{
+		name: "Burn",
+		new:  func() frontend.Circuit { return &circuits.BurnCircuit{} },
+		leaf: "Sender.ValueEGCT.C1.Y",
+		get:  func(c frontend.Circuit) frontend.Variable { return c.(*circuits.BurnCircuit).Sender.ValueEGCT.C1.Y },
+	},
+	{
+		name: "BalanceThreshold",
+		new:  func() frontend.Circuit { return &circuits.BalanceThresholdCircuit{} },
+		leaf: "UpperBound",
+		get:  func(c frontend.Circuit) frontend.Variable { return c.(*circuits.BalanceThresholdCircuit).UpperBound },
+	},
+	{
+		name: "EGCTDisclosure",
+		new:  func() frontend.Circuit { return &circuits.EGCTDisclosureCircuit{} },
+		leaf: "Holder.PrivateKey",
+		get: func(c frontend.Circuit) frontend.Variable {
+			return c.(*circuits.EGCTDisclosureCircuit).Holder.PrivateKey
+		},
+	},
+	{
+		name: "PCTDisclosure",
+		new:  func() frontend.Circuit { return &circuits.PCTDisclosureCircuit{} },
+		leaf: "PCT.AuthKey.X",
+		get:  func(c frontend.Circuit) frontend.Variable { return c.(*circuits.PCTDisclosureCircuit).PCT.AuthKey.X },
+	},
+	{
+		name: "AuditorRotation",
+		new:  func() frontend.Circuit { return &circuits.AuditorRotationCircuit{} },
+		leaf: "OldAuditor.PCT.Ciphertext[3]",
+		get: func(c frontend.Circuit) frontend.Variable {
+			return c.(*circuits.AuditorRotationCircuit).OldAuditor.PCT.Ciphertext[3]
+		},
+	},
+}
+
+// gives every leaf its own value, as decimal and hexadecimal strings and JSON numbers,
+// and lists the same values positionally: the public leaves then the secret ones, each in walk order
+func testInputs(t *testing.T, circuit frontend.Circuit) (map[string]json.RawMessage, map[string]*big.Int, []string, []string) {
+	t.Helper()
+	fields, err := CircuitFields(circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	named := make(map[string]json.RawMessage, len(fields))
+	values := make(map[string]*big.Int, len(fields))
+	var publicIns, privateIns []string
+	for i, field := range fields {
+		value := new(big.Int).Lsh(big.NewInt(int64(i+1)), 128)
+		values[field.Name] = value
+		switch i % 3 {
+		case 0:
+			named[field.Name] = json.RawMessage(strconv.Quote(value.String()))
+		case 1:
+			named[field.Name] = json.RawMessage(strconv.Quote(fmt.Sprintf("0x%x", value)))
+		default:
+			named[field.Name] = json.RawMessage(value.String())
+		}
+
+		if field.Public {
+			publicIns = append(publicIns, value.String())
+		} else {
+			privateIns = append(privateIns, value.String())
+		}
+	}
+	return named, values, publicIns, privateIns
+}
+
+func marshalWitness(t *testing.T, w witness.Witness) []byte {
+	t.Helper()
+	b, err := w.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func assertLeaf(t *testing.T, name string, v frontend.Variable, expected *big.Int) {
+	t.Helper()
+	var value big.Int
+	switch v := v.(type) {
+	case *big.Int:
+		value.Set(v)
+	case big.Int:
+		value.Set(&v)
+	default:
+		t.Fatalf("%s: expected a big.Int, got %T", name, v)
+	}
+	if value.Cmp(expected) != 0 {
+		t.Fatalf("%s: expected %s, got %s", name, expected, &value)
+	}
+}
+
+func TestNamedInputsMatchPositional(t *testing.T) {
+	for _, tt := range witnessCircuits {
+		t.Run(tt.name, func(t *testing.T) {
+			named, values, publicIns, privateIns := testInputs(t, tt.new())
+			if _, ok := named[tt.leaf]; !ok {
+				t.Fatalf("%s is not a field of the circuit", tt.leaf)
+			}
+
+			assignment := tt.new()
+			fromFields, err := GenerateWitnessFromFields(assignment, named)
+			if err != nil {
+				t.Fatal(err)
+			}
+			positional, err := GenerateWitness(publicIns, privateIns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(marshalWitness(t, fromFields), marshalWitness(t, positional)) {
+				t.Fatal("the named inputs and the positional inputs give different witnesses")
+			}
+			assertLeaf(t, tt.leaf, tt.get(assignment), values[tt.leaf])
+
+			// filling the assignment from the positional witness gives back the named values
+			assigned := tt.new()
+			if err := AssignWitness(assigned, positional); err != nil {
+				t.Fatal(err)
+			}
+			assertLeaf(t, tt.leaf, tt.get(assigned), values[tt.leaf])
+			w, err := frontend.NewWitness(assigned, ecc.BN254.ScalarField())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(marshalWitness(t, w), marshalWitness(t, positional)) {
+				t.Fatal("AssignWitness is not the inverse of frontend.NewWitness")
+			}
+		})
+	}
+}
+
+func TestGenerateWitnessFromFieldsRejects(t *testing.T) {
+	quote := func(s string) json.RawMessage { return json.RawMessage(strconv.Quote(s)) }
+	modulus := ecc.BN254.ScalarField()
+
+	tests := []struct {
+		name     string
+		modify   func(fields map[string]json.RawMessage)
+		expected []string
+	}{
+		{"missing field", func(f map[string]json.RawMessage) { delete(f, "Sender.PrivateKey") },
+			[]string{"missing fields: Sender.PrivateKey"}},
+		{"missing fields", func(f map[string]json.RawMessage) { delete(f, "Sender.ChainID"); delete(f, "Sender.PublicKey.P.X") },
+			[]string{"missing fields: Sender.PublicKey.P.X, Sender.ChainID"}},
+		{"unknown field", func(f map[string]json.RawMessage) { f["Sender.Nonce"] = quote("1") },
+			[]string{"unknown fields: Sender.Nonce"}},
+		{"gnark leaf name", func(f map[string]json.RawMessage) {
+			f["Sender_PrivateKey"] = f["Sender.PrivateKey"]
+			delete(f, "Sender.PrivateKey")
+		}, []string{"missing fields: Sender.PrivateKey", "unknown fields: Sender_PrivateKey"}},
+		{"not a number", func(f map[string]json.RawMessage) { f["Sender.Address"] = quote("abc") },
+			[]string{"malformed fields: Sender.Address"}},
+		{"negative", func(f map[string]json.RawMessage) { f["Sender.Address"] = quote("-1") },
+			[]string{"malformed fields: Sender.Address"}},
+		{"not reduced", func(f map[string]json.RawMessage) { f["Sender.Address"] = quote(modulus.String()) },
+			[]string{"malformed fields: Sender.Address"}},
+		{"not a string or number", func(f map[string]json.RawMessage) { f["Sender.Address"] = json.RawMessage(`[1]`) },
+			[]string{"malformed fields: Sender.Address"}},
+		{"every problem", func(f map[string]json.RawMessage) {
+			delete(f, "Sender.PrivateKey")
+			f["Sender.Nonce"] = quote("1")
+			f["Sender.Address"] = quote("0xzz")
+		}, []string{"missing fields: Sender.PrivateKey", "unknown fields: Sender.Nonce", "malformed fields: Sender.Address"}},
+	}
+
+	for _, tt := range tests {
+		fields, _, _, _ := testInputs(t, &circuits.RegistrationCircuit{})
+		tt.modify(fields)
+		_, err := GenerateWitnessFromFields(&circuits.RegistrationCircuit{}, fields)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+			continue
+		}
+		for _, expected := range tt.expected {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("%s: expected %q in %q", tt.name, expected, err)
+			}
+		}
+	}
+}
+
+func TestFieldPath(t *testing.T) {
+	tests := map[string]string{
+		"Amount":                   "Amount",
+		"Sender_PublicKey_P_X":     "Sender.PublicKey.P.X",
+		"Auditor_PCT_Ciphertext_2": "Auditor.PCT.Ciphertext[2]",
+		"Values_1_2":               "Values[1][2]",
+	}
+	for leaf, expected := range tests {
+		if got := fieldPath(leaf); got != expected {
+			t.Errorf("%s: expected %s, got %s", leaf, expected, got)
+		}
+	}
+}